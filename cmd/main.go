@@ -5,9 +5,15 @@ import (
 	"TodoApp/cfg"
 	_ "TodoApp/docs"
 	"TodoApp/internal/handler"
+	"TodoApp/internal/metrics"
+	"TodoApp/internal/migrate"
 	"TodoApp/internal/repository"
 	"TodoApp/internal/service"
+	"TodoApp/internal/storage"
+	"TodoApp/internal/tracing"
+	"TodoApp/schema"
 	"context"
+	"flag"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -31,6 +37,9 @@ import (
 // @name Authorization
 
 func main() {
+	autoMigrate := flag.Bool("auto-migrate", false, "apply pending migrations before starting the server")
+	flag.Parse()
+
 	logrus.SetFormatter(new(logrus.JSONFormatter))
 	if err := cfg.InitConfig(); err != nil {
 		logrus.Fatalf("error initializating config: %s", err.Error())
@@ -40,6 +49,16 @@ func main() {
 		logrus.Fatalf("error loading .env file: %s", err.Error())
 	}
 
+	shutdownTracing, err := tracing.Init(tracing.Config{
+		Exporter:     viper.GetString("tracing.exporter"),
+		OTLPEndpoint: viper.GetString("tracing.otlp_endpoint"),
+		OTLPInsecure: viper.GetBool("tracing.otlp_insecure"),
+		SampleRatio:  viper.GetFloat64("tracing.sample_ratio"),
+	})
+	if err != nil {
+		logrus.Fatalf("error initializing tracing: %s", err.Error())
+	}
+
 	db, err := repository.NewPostgresDB(repository.Config{
 		Host:     viper.GetString("db.host"),
 		SSLMode:  viper.GetString("db.sslmode"),
@@ -63,9 +82,72 @@ func main() {
 		return
 	}
 
-	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
-	handlers := handler.NewHandler(services)
+	metrics.RegisterDB(db.DB, viper.GetString("db.name"))
+
+	migrations, err := migrate.Load(schema.Migrations)
+	if err != nil {
+		logrus.Fatalf("error loading migrations: %s", err.Error())
+	}
+	migrator := migrate.NewMigrator(db, migrations)
+
+	if flag.Arg(0) == "migrate" {
+		if err = runMigrate(migrator, flag.Args()[1:]); err != nil {
+			logrus.Fatalf("error migrating DB: %s", err.Error())
+		}
+		return
+	}
+
+	if *autoMigrate || viper.GetBool("db.auto_migrate") {
+		if err = migrator.Up(context.Background()); err != nil {
+			logrus.Fatalf("error migrating DB: %s", err.Error())
+		}
+	}
+
+	passwordHasher, err := service.NewPasswordHasher(viper.GetString("auth.password_hasher"))
+	if err != nil {
+		logrus.Fatalf("error initializing password hasher: %s", err.Error())
+	}
+
+	var keyConfigs []service.KeyConfig
+	if err = viper.UnmarshalKey("auth.keys", &keyConfigs); err != nil {
+		logrus.Fatalf("error reading jwt keys config: %s", err.Error())
+	}
+
+	keys, err := service.NewKeySet(viper.GetString("auth.signing_key_id"), keyConfigs)
+	if err != nil {
+		logrus.Fatalf("error loading jwt keys: %s", err.Error())
+	}
+
+	var blobs storage.BlobStore
+	switch store := viper.GetString("attachments.store"); store {
+	case "local":
+		blobs, err = storage.NewLocalStore(viper.GetString("attachments.dir"))
+	default:
+		logrus.Fatalf("unknown attachments store %q", store)
+	}
+	if err != nil {
+		logrus.Fatalf("error initializing attachments store: %s", err.Error())
+	}
+
+	repos := repository.NewRepository(db, viper.GetDuration("db.query_timeout"), viper.GetString("search.config"))
+	if err = repos.Search.Configure(context.Background()); err != nil {
+		logrus.Errorf("error configuring search: %s", err.Error())
+	}
+	services := service.NewService(repos, service.Config{
+		Auth: service.AuthConfig{
+			AccessTokenTTL:  viper.GetDuration("auth.access_token_ttl"),
+			RefreshTokenTTL: viper.GetDuration("auth.refresh_token_ttl"),
+			PasswordHasher:  passwordHasher,
+			Keys:            keys,
+		},
+		InvitationTTL:     viper.GetDuration("invitations.ttl"),
+		TrashRetention:    viper.GetDuration("trash.retention"),
+		Blobs:             blobs,
+		MaxAttachmentSize: viper.GetInt64("attachments.max_size"),
+		Migrations:        migrator,
+		HealthTimeout:     viper.GetDuration("health.timeout"),
+	})
+	handlers := handler.NewHandler(services, viper.GetDuration("attachments.transfer_timeout"))
 	srv := new(TodoApp.Server)
 
 	go func() {
@@ -76,11 +158,22 @@ func main() {
 
 	logrus.Info("server started")
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if purgeInterval := viper.GetDuration("trash.purge_interval"); purgeInterval > 0 {
+		go services.Trash.RunPurge(jobsCtx, purgeInterval)
+	} else {
+		logrus.Warnf("trash.purge_interval is %s, expired trash is not purged", purgeInterval)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
 	logrus.Info("server shutting down")
+	services.Health.Shutdown()
+	time.Sleep(viper.GetDuration("health.drain_delay"))
+	stopJobs()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err = srv.Shutdown(ctx); err != nil {
@@ -88,4 +181,8 @@ func main() {
 	} else {
 		logrus.Info("server stopped")
 	}
+
+	if err = shutdownTracing(ctx); err != nil {
+		logrus.Errorf("error flushing traces: %v", err)
+	}
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"TodoApp/internal/migrate"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: todo-app migrate up|down|status|to <version>"
+
+// runMigrate runs the migrate subcommand with the arguments that follow it.
+func runMigrate(migrator *migrate.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+
+		return migrator.To(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "Applied At\tMigration")
+		for _, status := range statuses {
+			appliedAt := "Pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.DateTime)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%d_%s.sql\n", appliedAt, status.Version, status.Name)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary getListActivity
+// @Security ApiKeyAuth
+// @Tags activity
+// @Description get the history of a list and its items, the latest first by default
+// @ID get-list-activity
+// @Produce json
+// @Param id path int true "list id"
+// @Param limit query int false "page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "sort column, a leading minus sorts descending" Enums(created_at, -created_at) default(-created_at)
+// @Success 200 {object} activityPageResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/activity [get]
+func (h *Handler) getListActivity(c *gin.Context) {
+	h.getActivity(c, "list not found", h.services.Activity.GetByList)
+}
+
+// @Summary getItemActivity
+// @Security ApiKeyAuth
+// @Tags activity
+// @Description get the history of an item, the latest first by default
+// @ID get-item-activity
+// @Produce json
+// @Param id path int true "item id"
+// @Param limit query int false "page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "sort column, a leading minus sorts descending" Enums(created_at, -created_at) default(-created_at)
+// @Success 200 {object} activityPageResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/activity [get]
+func (h *Handler) getItemActivity(c *gin.Context) {
+	h.getActivity(c, "item not found", h.services.Activity.GetByItem)
+}
+
+func (h *Handler) getActivity(c *gin.Context, notFound string, get func(ctx context.Context, userId, id int, page model.PageQuery) ([]model.Activity, string, error)) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	page, err := parsePageQuery(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	activities, next, err := get(c.Request.Context(), userId, id, page)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, notFound)
+			return
+		}
+		collectionErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, activityPageResponse{Data: activities, NextCursor: next})
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/storage"
+	"bufio"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at.
+const sniffLength = 512
+
+// @Summary uploadAttachment
+// @Security ApiKeyAuth
+// @Tags attachment
+// @Description attach a file to an item, the file is sent in the file field of a multipart form
+// @ID upload-attachment
+// @Accept mpfd
+// @Produce json
+// @Param id path int true "item id"
+// @Param file formData file true "file to attach"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 413 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/attachments [post]
+func (h *Handler) uploadAttachment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	part, err := filePart(reader)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer part.Close()
+
+	body := bufio.NewReaderSize(part, sniffLength)
+	input := model.Attachment{
+		Filename:    filepath.Base(part.FileName()),
+		ContentType: partContentType(part, body),
+	}
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.Attachment.Upload(c.Request.Context(), userId, itemId, input.Filename, input.ContentType, body)
+	if err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+// @Summary getAllAttachments
+// @Security ApiKeyAuth
+// @Tags attachment
+// @Description get the attachments of an item, the oldest first
+// @ID get-all-attachments
+// @Produce json
+// @Param id path int true "item id"
+// @Success 200 {array} model.Attachment
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/attachments [get]
+func (h *Handler) getAllAttachments(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	attachments, err := h.services.Attachment.GetAll(c.Request.Context(), userId, itemId)
+	if err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// @Summary downloadAttachment
+// @Security ApiKeyAuth
+// @Tags attachment
+// @Description download the contents of an attachment with its content type
+// @ID download-attachment
+// @Produce octet-stream
+// @Param id path int true "item id"
+// @Param attachment_id path int true "attachment id"
+// @Success 200 {file} file
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/attachments/{attachment_id} [get]
+func (h *Handler) downloadAttachment(c *gin.Context) {
+	userId, itemId, attachmentId, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, blob, err := h.services.Attachment.Open(c.Request.Context(), userId, itemId, attachmentId)
+	if err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+	defer blob.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, blob, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Summary deleteAttachment
+// @Security ApiKeyAuth
+// @Tags attachment
+// @Description delete an attachment with its contents
+// @ID delete-attachment
+// @Produce json
+// @Param id path int true "item id"
+// @Param attachment_id path int true "attachment id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/attachments/{attachment_id} [delete]
+func (h *Handler) deleteAttachment(c *gin.Context) {
+	userId, itemId, attachmentId, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Attachment.Delete(c.Request.Context(), userId, itemId, attachmentId); err != nil {
+		attachmentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// filePart skips to the file field of a multipart form, so the file is
+// streamed to the blob store instead of being buffered.
+func filePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("file field is required")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == "file" && part.FileName() != "" {
+			return part, nil
+		}
+		_ = part.Close()
+	}
+}
+
+// partContentType takes the content type the client sent and detects it from
+// the first bytes of the file if the client did not know it.
+func partContentType(part *multipart.Part, body *bufio.Reader) string {
+	contentType := part.Header.Get("Content-Type")
+	if contentType != "" && contentType != "application/octet-stream" {
+		return contentType
+	}
+
+	head, _ := body.Peek(sniffLength)
+	return http.DetectContentType(head)
+}
+
+func attachmentParams(c *gin.Context) (userId, itemId, attachmentId int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	itemId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return 0, 0, 0, false
+	}
+
+	attachmentId, err = strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid attachment id param")
+		return 0, 0, 0, false
+	}
+
+	return userId, itemId, attachmentId, true
+}
+
+func attachmentErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, storage.ErrBlobNotFound):
+		newErrorResponse(c, http.StatusNotFound, "item or attachment not found")
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrListArchived):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, model.ErrAttachmentTooLarge):
+		newErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"TodoApp/internal/model"
+	"TodoApp/internal/service"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -23,7 +25,7 @@ func (h *Handler) signUp(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.Authorization.CreateUser(input)
+	id, err := h.services.Authorization.CreateUser(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -46,7 +48,8 @@ type SignInInput struct {
 // @Accept json
 // @Produce json
 // @Param input body SignInInput true "sign in info"
-// @Success 200 {integer} integer 1
+// @Success 200 {object} model.Tokens
+// @Failure 401 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /auth/sign-in [post]
 func (h *Handler) signIn(c *gin.Context) {
@@ -56,13 +59,93 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+	tokens, err := h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"token": token,
-	})
+	c.JSON(http.StatusOK, tokens)
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// @Summary refresh
+// @Tags auth
+// @Description exchange refresh token for a new token pair
+// @ID refresh-token
+// @Accept json
+// @Produce json
+// @Param input body RefreshTokenInput true "refresh token"
+// @Success 200 {object} model.Tokens
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /auth/refresh [post]
+func (h *Handler) refresh(c *gin.Context) {
+	var input RefreshTokenInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.services.Authorization.RefreshToken(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary logout
+// @Tags auth
+// @Description revoke session of the refresh token
+// @ID logout
+// @Accept json
+// @Produce json
+// @Param input body RefreshTokenInput true "refresh token"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /auth/logout [post]
+func (h *Handler) logout(c *gin.Context) {
+	var input RefreshTokenInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.Authorization.Logout(c.Request.Context(), input.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary jwks
+// @Tags auth
+// @Description public keys to verify access tokens
+// @ID jwks
+// @Produce json
+// @Success 200 {object} model.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) jwks(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Authorization.JWKS())
 }
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary createComment
+// @Security ApiKeyAuth
+// @Tags comment
+// @Description comment on an item, every member of the list may comment, @username mentions of members are stored
+// @ID create-comment
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param input body model.Comment true "comment info"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/comments [post]
+func (h *Handler) createComment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	var input model.Comment
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.Comment.Create(c.Request.Context(), userId, itemId, input)
+	if err != nil {
+		commentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+// @Summary getAllComments
+// @Security ApiKeyAuth
+// @Tags comment
+// @Description get comments of an item, the oldest first
+// @ID get-all-comments
+// @Produce json
+// @Param id path int true "item id"
+// @Success 200 {array} model.Comment
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/comments [get]
+func (h *Handler) getAllComments(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	comments, err := h.services.Comment.GetAll(c.Request.Context(), userId, itemId)
+	if err != nil {
+		commentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// @Summary updateComment
+// @Security ApiKeyAuth
+// @Tags comment
+// @Description change the body of a comment, only the author may do it
+// @ID update-comment
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param comment_id path int true "comment id"
+// @Param input body model.UpdateCommentInput true "comment info"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/comments/{comment_id} [put]
+func (h *Handler) updateComment(c *gin.Context) {
+	userId, itemId, commentId, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	var input model.UpdateCommentInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.Comment.Update(c.Request.Context(), userId, itemId, commentId, input); err != nil {
+		commentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary deleteComment
+// @Security ApiKeyAuth
+// @Tags comment
+// @Description delete a comment, authors may delete their own comments, list owners any comment
+// @ID delete-comment
+// @Produce json
+// @Param id path int true "item id"
+// @Param comment_id path int true "comment id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/comments/{comment_id} [delete]
+func (h *Handler) deleteComment(c *gin.Context) {
+	userId, itemId, commentId, ok := commentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Comment.Delete(c.Request.Context(), userId, itemId, commentId); err != nil {
+		commentErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func commentParams(c *gin.Context) (userId, itemId, commentId int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	itemId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return 0, 0, 0, false
+	}
+
+	commentId, err = strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid comment id param")
+		return 0, 0, 0, false
+	}
+
+	return userId, itemId, commentId, true
+}
+
+func commentErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "item or comment not found")
+	case errors.Is(err, model.ErrForbidden), errors.Is(err, model.ErrNotCommentAuthor):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary createFilter
+// @Security ApiKeyAuth
+// @Tags filter
+// @Description save a named filter over the items of all lists of the user. Nodes of the expression combine other nodes with and, or and not, or compare a field with a value: text fields title and description support eq, ne, contains and in; priority supports eq, ne and in; done supports eq; list_id and label_id support eq, ne and in; the times start_at, due_at, completed_at, created_at and updated_at support lt, lte, gt, gte and is_null, with RFC 3339 or relative values like now+7d
+// @ID create-filter
+// @Accept json
+// @Produce json
+// @Param input body model.SavedFilter true "filter info"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/filters [post]
+func (h *Handler) createFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input model.SavedFilter
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.SavedFilter.Create(c.Request.Context(), userId, input)
+	if err != nil {
+		filterErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+// @Summary getAllFilters
+// @Security ApiKeyAuth
+// @Tags filter
+// @Description get all saved filters of the user
+// @ID get-all-filters
+// @Produce json
+// @Success 200 {array} model.SavedFilter
+// @Failure 500 {object} errorResponse
+// @Router /api/filters [get]
+func (h *Handler) getAllFilters(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	filters, err := h.services.SavedFilter.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"data": filters})
+}
+
+// @Summary getFilterById
+// @Security ApiKeyAuth
+// @Tags filter
+// @Description get saved filter by id
+// @ID get-filter-by-id
+// @Produce json
+// @Param id path int true "filter id"
+// @Success 200 {object} model.SavedFilter
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/filters/{id} [get]
+func (h *Handler) getFilterById(c *gin.Context) {
+	userId, filterId, ok := filterParams(c)
+	if !ok {
+		return
+	}
+
+	filter, err := h.services.SavedFilter.GetById(c.Request.Context(), userId, filterId)
+	if err != nil {
+		filterErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+// @Summary updateFilter
+// @Security ApiKeyAuth
+// @Tags filter
+// @Description rename saved filter or replace its expression
+// @ID update-filter
+// @Accept json
+// @Produce json
+// @Param id path int true "filter id"
+// @Param input body model.UpdateFilterInput true "filter info"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/filters/{id} [put]
+func (h *Handler) updateFilter(c *gin.Context) {
+	userId, filterId, ok := filterParams(c)
+	if !ok {
+		return
+	}
+
+	var input model.UpdateFilterInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.SavedFilter.Update(c.Request.Context(), userId, filterId, input); err != nil {
+		filterErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary deleteFilter
+// @Security ApiKeyAuth
+// @Tags filter
+// @Description delete saved filter
+// @ID delete-filter
+// @Produce json
+// @Param id path int true "filter id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/filters/{id} [delete]
+func (h *Handler) deleteFilter(c *gin.Context) {
+	userId, filterId, ok := filterParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.SavedFilter.Delete(c.Request.Context(), userId, filterId); err != nil {
+		filterErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary getFilterItems
+// @Security ApiKeyAuth
+// @Tags filter
+// @Description get the items matching a saved filter across all lists of the user
+// @ID get-filter-items
+// @Produce json
+// @Param id path int true "filter id"
+// @Param limit query int false "page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "sort column, a leading minus sorts descending" Enums(position, -position, created_at, -created_at, due_at, -due_at, title, -title, priority, -priority) default(due_at)
+// @Success 200 {object} itemsPageResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/filters/{id}/items [get]
+func (h *Handler) getFilterItems(c *gin.Context) {
+	userId, filterId, ok := filterParams(c)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageQuery(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, next, err := h.services.SavedFilter.GetItems(c.Request.Context(), userId, filterId, page)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "filter not found")
+			return
+		}
+		collectionErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, itemsPageResponse{Data: items, NextCursor: next})
+}
+
+func filterParams(c *gin.Context) (userId, filterId int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	filterId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return 0, 0, false
+	}
+
+	return userId, filterId, true
+}
+
+func filterErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "filter not found")
+	case errors.Is(err, model.ErrFilterExists):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,53 +2,153 @@ package handler
 
 import (
 	"TodoApp/internal/service"
+	"TodoApp/internal/tracing"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"net/http"
+	"time"
 )
 
 type Handler struct {
 	services *service.Service
+	// transferTimeout bounds uploads and downloads of attachments instead of
+	// the server timeouts, no limit if it is 0.
+	transferTimeout time.Duration
 }
 
-func NewHandler(services *service.Service) *Handler {
-	return &Handler{services: services}
+func NewHandler(services *service.Service, transferTimeout time.Duration) *Handler {
+	return &Handler{services: services, transferTimeout: transferTimeout}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	router.Use(httpMetrics, otelgin.Middleware(tracing.ServiceName))
 	auth := router.Group("/auth")
 	{
 		auth.POST("/sign-in", h.signIn)
 		auth.POST("/sign-up", h.signUp)
+		auth.POST("/refresh", h.refresh)
+		auth.POST("/logout", h.logout)
 	}
 
 	api := router.Group("/api", h.userIdentity)
 	{
+		tokens := api.Group("/tokens", h.sessionOnly)
+		{
+			tokens.POST("/", h.createToken)
+			tokens.GET("/", h.getAllTokens)
+			tokens.DELETE("/:id", h.revokeToken)
+		}
+
 		lists := api.Group("/lists")
 		{
 			lists.POST("/", h.createList)
 			lists.GET("/", h.getAllLists)
+			lists.POST("/reorder", h.reorderLists)
 			lists.GET("/:id", h.getListById)
 			lists.PUT("/:id", h.updateList)
 			lists.DELETE("/:id", h.deleteList)
+			lists.POST("/:id/archive", h.archiveList)
+			lists.POST("/:id/unarchive", h.unarchiveList)
+			lists.GET("/:id/activity", h.getListActivity)
 
 			items := lists.Group(":id/items")
 			{
 				items.POST("/", h.createItem)
 				items.GET("/", h.getAllItems)
+				items.POST("/reorder", h.reorderItems)
+			}
+
+			lists.POST("/:id/invitations", h.createInvitation)
+
+			members := lists.Group(":id/members")
+			{
+				members.GET("/", h.getAllMembers)
+				members.PUT("/:user_id", h.updateMember)
+				members.DELETE("/:user_id", h.deleteMember)
 			}
 		}
 
+		invitations := api.Group("/invitations")
+		{
+			invitations.GET("/", h.getPendingInvitations)
+			invitations.POST("/:id/accept", h.acceptInvitation)
+			invitations.POST("/:id/decline", h.declineInvitation)
+		}
+
 		items := api.Group("/items")
 		{
+			items.GET("/", h.getAllUserItems)
 			items.GET("/:id", h.getItemById)
 			items.PUT("/:id", h.updateItem)
 			items.DELETE("/:id", h.deleteItem)
+			items.POST("/:id/move", h.moveItem)
+			items.POST("/:id/copy", h.copyItem)
+			items.GET("/:id/activity", h.getItemActivity)
+			items.POST("/:id/labels/:label_id", h.attachLabel)
+			items.DELETE("/:id/labels/:label_id", h.detachLabel)
+
+			subtasks := items.Group("/:id/subtasks")
+			{
+				subtasks.POST("/", h.createSubtask)
+				subtasks.GET("/", h.getAllSubtasks)
+				subtasks.POST("/reorder", h.reorderSubtasks)
+				subtasks.PUT("/:subtask_id", h.updateSubtask)
+				subtasks.DELETE("/:subtask_id", h.deleteSubtask)
+			}
+
+			comments := items.Group("/:id/comments")
+			{
+				comments.POST("/", h.createComment)
+				comments.GET("/", h.getAllComments)
+				comments.PUT("/:comment_id", h.updateComment)
+				comments.DELETE("/:comment_id", h.deleteComment)
+			}
+
+			attachments := items.Group("/:id/attachments")
+			{
+				attachments.POST("/", h.transferDeadline, h.uploadAttachment)
+				attachments.GET("/", h.getAllAttachments)
+				attachments.GET("/:attachment_id", h.transferDeadline, h.downloadAttachment)
+				attachments.DELETE("/:attachment_id", h.deleteAttachment)
+			}
+		}
+
+		labels := api.Group("/labels")
+		{
+			labels.POST("/", h.createLabel)
+			labels.GET("/", h.getAllLabels)
+			labels.PUT("/:id", h.updateLabel)
+			labels.DELETE("/:id", h.deleteLabel)
 		}
+
+		filters := api.Group("/filters")
+		{
+			filters.POST("/", h.createFilter)
+			filters.GET("/", h.getAllFilters)
+			filters.GET("/:id", h.getFilterById)
+			filters.PUT("/:id", h.updateFilter)
+			filters.DELETE("/:id", h.deleteFilter)
+			filters.GET("/:id/items", h.getFilterItems)
+		}
+
+		trash := api.Group("/trash")
+		{
+			trash.GET("/", h.getTrash)
+			trash.POST("/:type/:id/restore", h.restoreFromTrash)
+			trash.DELETE("/:type/:id", h.purgeFromTrash)
+		}
+
+		api.GET("/search", h.search)
 	}
+	router.GET("/.well-known/jwks.json", h.jwks)
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return router
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary healthz
+// @Tags health
+// @Description liveness probe, ok as long as the process serves requests
+// @ID healthz
+// @Produce json
+// @Success 200 {object} statusResponse
+// @Router /healthz [get]
+func (h *Handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, statusResponse{Status: model.HealthOK})
+}
+
+// @Summary readyz
+// @Tags health
+// @Description readiness probe, checks the database and migrations and fails once the server is shutting down
+// @ID readyz
+// @Produce json
+// @Success 200 {object} model.Readiness
+// @Failure 503 {object} model.Readiness
+// @Router /readyz [get]
+func (h *Handler) readyz(c *gin.Context) {
+	readiness := h.services.Health.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if readiness.Status != model.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, readiness)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary createInvitation
+// @Security ApiKeyAuth
+// @Tags invitation
+// @Description invite a user to a list, only owners may invite
+// @ID create-invitation
+// @Accept json
+// @Produce json
+// @Param id path int true "list id"
+// @Param input body model.InviteInput true "invitation info"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/invitations [post]
+func (h *Handler) createInvitation(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+		return
+	}
+
+	var input model.InviteInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = model.ValidateRole(input.Role); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.Invitation.Create(c.Request.Context(), userId, listId, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			newErrorResponse(c, http.StatusNotFound, "list not found")
+		case errors.Is(err, model.ErrUserNotFound):
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, model.ErrForbidden):
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, model.ErrAlreadyMember), errors.Is(err, model.ErrAlreadyInvited):
+			newErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+// @Summary getPendingInvitations
+// @Security ApiKeyAuth
+// @Tags invitation
+// @Description get pending invitations of the user
+// @ID get-pending-invitations
+// @Produce json
+// @Success 200 {array} model.Invitation
+// @Failure 500 {object} errorResponse
+// @Router /api/invitations [get]
+func (h *Handler) getPendingInvitations(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	invitations, err := h.services.Invitation.GetPending(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"data": invitations})
+}
+
+// @Summary acceptInvitation
+// @Security ApiKeyAuth
+// @Tags invitation
+// @Description accept invitation and join the list
+// @ID accept-invitation
+// @Produce json
+// @Param id path int true "invitation id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 410 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/invitations/{id}/accept [post]
+func (h *Handler) acceptInvitation(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err = h.services.Invitation.Accept(c.Request.Context(), userId, id); err != nil {
+		invitationErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary declineInvitation
+// @Security ApiKeyAuth
+// @Tags invitation
+// @Description decline invitation
+// @ID decline-invitation
+// @Produce json
+// @Param id path int true "invitation id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 410 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/invitations/{id}/decline [post]
+func (h *Handler) declineInvitation(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err = h.services.Invitation.Decline(c.Request.Context(), userId, id); err != nil {
+		invitationErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func invitationErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "invitation not found")
+	case errors.Is(err, model.ErrInvitationExpired):
+		newErrorResponse(c, http.StatusGone, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
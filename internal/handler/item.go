@@ -7,12 +7,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // @Summary createItem
 // @Security ApiKeyAuth
 // @Tags item
-// @Description creates an item
+// @Description creates an item, recurrence is an RRULE subset (FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL)
 // @ID create-item
 // @Accept json
 // @Produce json
@@ -20,6 +21,9 @@ import (
 // @Param id path int true "list id"
 // @Success 200 {integer} integer 1
 // @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/lists/{id}/items [post]
 func (h *Handler) createItem(c *gin.Context) {
@@ -40,8 +44,25 @@ func (h *Handler) createItem(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.TodoItem.Create(userId, listId, input)
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.TodoItem.Create(c.Request.Context(), userId, listId, input)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "list not found")
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, model.ErrListArchived) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -54,11 +75,20 @@ func (h *Handler) createItem(c *gin.Context) {
 // @Summary getAllItems
 // @Security ApiKeyAuth
 // @Tags item
-// @Description get all items
+// @Description get all items of the list in their manual order
 // @ID get-all-items
 // @Produce json
 // @Param id path int true "list id"
-// @Success 200 {integer} integer 1
+// @Param due_before query string false "only items due before this RFC 3339 time"
+// @Param overdue query bool false "only items that are not done and past their due date"
+// @Param label query int false "only items with this label id"
+// @Param priority query string false "only items with this priority" Enums(none, low, medium, high, urgent)
+// @Param limit query int false "page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param done query bool false "only done or only open items"
+// @Param q query string false "only items whose title or description contains this text"
+// @Param sort query string false "sort column, a leading minus sorts descending" Enums(position, -position, created_at, -created_at, due_at, -due_at, title, -title, priority, -priority) default(position)
+// @Success 200 {object} itemsPageResponse
 // @Failure 400 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/lists/{id}/items [get]
@@ -74,13 +104,118 @@ func (h *Handler) getAllItems(c *gin.Context) {
 		return
 	}
 
-	items, err := h.services.TodoItem.GetAll(userId, listId)
+	filter, err := parseItemFilter(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := parsePageQuery(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, next, err := h.services.TodoItem.GetAll(c.Request.Context(), userId, listId, filter, page)
+	if err != nil {
+		collectionErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	c.JSON(http.StatusOK, itemsPageResponse{Data: items, NextCursor: next})
+}
+
+// @Summary reorderItems
+// @Security ApiKeyAuth
+// @Tags item
+// @Description move an item right before or after another item of the list
+// @ID reorder-items
+// @Accept json
+// @Produce json
+// @Param id path int true "list id"
+// @Param input body model.ReorderInput true "item id and its new neighbour"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/items/reorder [post]
+func (h *Handler) reorderItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+		return
+	}
+
+	var input model.ReorderInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = h.services.TodoItem.Reorder(c.Request.Context(), userId, listId, input); err != nil {
+		reorderErrorResponse(c, err, "list or item not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary getAllUserItems
+// @Security ApiKeyAuth
+// @Tags item
+// @Description get items across all lists of the user
+// @ID get-all-user-items
+// @Produce json
+// @Param due_before query string false "only items due before this RFC 3339 time"
+// @Param overdue query bool false "only items that are not done and past their due date"
+// @Param label query int false "only items with this label id"
+// @Param priority query string false "only items with this priority" Enums(none, low, medium, high, urgent)
+// @Param limit query int false "page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param done query bool false "only done or only open items"
+// @Param q query string false "only items whose title or description contains this text"
+// @Param sort query string false "sort column, a leading minus sorts descending" Enums(position, -position, created_at, -created_at, due_at, -due_at, title, -title, priority, -priority) default(due_at)
+// @Success 200 {object} itemsPageResponse
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items [get]
+func (h *Handler) getAllUserItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	filter, err := parseItemFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := parsePageQuery(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, next, err := h.services.TodoItem.GetAllByUser(c.Request.Context(), userId, filter, page)
+	if err != nil {
+		collectionErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, itemsPageResponse{Data: items, NextCursor: next})
 }
 
 // @Summary getItemById
@@ -106,7 +241,7 @@ func (h *Handler) getItemById(c *gin.Context) {
 		return
 	}
 
-	item, err := h.services.TodoItem.GetById(userId, itemId)
+	item, err := h.services.TodoItem.GetById(c.Request.Context(), userId, itemId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			newErrorResponse(c, http.StatusNotFound, "item not found")
@@ -122,7 +257,7 @@ func (h *Handler) getItemById(c *gin.Context) {
 // @Summary updateItem
 // @Security ApiKeyAuth
 // @Tags item
-// @Description update item by id
+// @Description update item by id, completing a recurring item creates its next occurrence
 // @ID update-item-by-id
 // @Accept json
 // @Produce json
@@ -130,6 +265,9 @@ func (h *Handler) getItemById(c *gin.Context) {
 // @Param input body model.UpdateItemInput true "item info"
 // @Success 200 {integer} integer 1
 // @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/items/{id} [put]
 func (h *Handler) updateItem(c *gin.Context) {
@@ -150,12 +288,25 @@ func (h *Handler) updateItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoItem.Update(userId, itemId, updateItemInput)
+	if err = updateItemInput.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.services.TodoItem.Update(c.Request.Context(), userId, itemId, updateItemInput)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			newErrorResponse(c, http.StatusNotFound, "item not found")
 			return
 		}
+		if errors.Is(err, model.ErrForbidden) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, model.ErrListArchived) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -166,12 +317,15 @@ func (h *Handler) updateItem(c *gin.Context) {
 // @Summary deleteItem
 // @Security ApiKeyAuth
 // @Tags item
-// @Description delete item by id
+// @Description move item to the trash
 // @ID update-item-by-id
 // @Produce json
 // @Param id path int true "item id"
 // @Success 200 {integer} integer 1
 // @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/items/{id} [delete]
 func (h *Handler) deleteItem(c *gin.Context) {
@@ -186,15 +340,168 @@ func (h *Handler) deleteItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoItem.Delete(userId, itemId)
+	err = h.services.TodoItem.Delete(c.Request.Context(), userId, itemId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			newErrorResponse(c, http.StatusNotFound, "item not found")
 			return
 		}
+		if errors.Is(err, model.ErrForbidden) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, model.ErrListArchived) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	c.JSON(http.StatusOK, statusResponse{"success"})
 }
+
+func parseItemFilter(c *gin.Context) (model.ItemFilter, error) {
+	var filter model.ItemFilter
+
+	if dueBefore := c.Query("due_before"); dueBefore != "" {
+		t, err := time.Parse(time.RFC3339, dueBefore)
+		if err != nil {
+			return filter, errors.New("invalid due_before param")
+		}
+		filter.DueBefore = &t
+	}
+
+	if overdue := c.Query("overdue"); overdue != "" {
+		var err error
+		filter.Overdue, err = strconv.ParseBool(overdue)
+		if err != nil {
+			return filter, errors.New("invalid overdue param")
+		}
+	}
+
+	if label := c.Query("label"); label != "" {
+		labelId, err := strconv.Atoi(label)
+		if err != nil {
+			return filter, errors.New("invalid label param")
+		}
+		filter.LabelId = &labelId
+	}
+
+	if priority := c.Query("priority"); priority != "" {
+		if err := model.ValidatePriority(priority); err != nil {
+			return filter, err
+		}
+		filter.Priority = &priority
+	}
+
+	if done := c.Query("done"); done != "" {
+		isDone, err := strconv.ParseBool(done)
+		if err != nil {
+			return filter, errors.New("invalid done param")
+		}
+		filter.Done = &isDone
+	}
+
+	if q := c.Query("q"); q != "" {
+		filter.Query = &q
+	}
+
+	return filter, nil
+}
+
+// @Summary moveItem
+// @Security ApiKeyAuth
+// @Tags item
+// @Description move an item to the end of another list, both lists must be writable
+// @ID move-item
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param input body model.MoveItemInput true "target list"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/move [post]
+func (h *Handler) moveItem(c *gin.Context) {
+	userId, itemId, input, ok := moveItemParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.TodoItem.Move(c.Request.Context(), userId, itemId, input); err != nil {
+		moveItemErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary copyItem
+// @Security ApiKeyAuth
+// @Tags item
+// @Description copy an item with its labels and subtasks to the end of a writable list
+// @ID copy-item
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param input body model.MoveItemInput true "target list"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/copy [post]
+func (h *Handler) copyItem(c *gin.Context) {
+	userId, itemId, input, ok := moveItemParams(c)
+	if !ok {
+		return
+	}
+
+	id, err := h.services.TodoItem.Copy(c.Request.Context(), userId, itemId, input)
+	if err != nil {
+		moveItemErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func moveItemParams(c *gin.Context) (userId, itemId int, input model.MoveItemInput, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, input, false
+	}
+
+	itemId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return 0, 0, input, false
+	}
+
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return 0, 0, input, false
+	}
+
+	return userId, itemId, input, true
+}
+
+func moveItemErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "item or list not found")
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrListArchived):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
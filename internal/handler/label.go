@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary createLabel
+// @Security ApiKeyAuth
+// @Tags label
+// @Description create label
+// @ID create-label
+// @Accept json
+// @Produce json
+// @Param input body model.Label true "label info"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/labels [post]
+func (h *Handler) createLabel(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input model.Label
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.Label.Create(c.Request.Context(), userId, input)
+	if err != nil {
+		if errors.Is(err, model.ErrLabelExists) {
+			newErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+// @Summary getAllLabels
+// @Security ApiKeyAuth
+// @Tags label
+// @Description get all labels of the user
+// @ID get-all-labels
+// @Produce json
+// @Success 200 {array} model.Label
+// @Failure 500 {object} errorResponse
+// @Router /api/labels [get]
+func (h *Handler) getAllLabels(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	labels, err := h.services.Label.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"data": labels})
+}
+
+// @Summary updateLabel
+// @Security ApiKeyAuth
+// @Tags label
+// @Description rename or recolor label
+// @ID update-label
+// @Accept json
+// @Produce json
+// @Param id path int true "label id"
+// @Param input body model.UpdateLabelInput true "label info"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/labels/{id} [put]
+func (h *Handler) updateLabel(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input model.UpdateLabelInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = h.services.Label.Update(c.Request.Context(), userId, id, input); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			newErrorResponse(c, http.StatusNotFound, "label not found")
+		case errors.Is(err, model.ErrLabelExists):
+			newErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary deleteLabel
+// @Security ApiKeyAuth
+// @Tags label
+// @Description delete label, it is detached from all items
+// @ID delete-label
+// @Produce json
+// @Param id path int true "label id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/labels/{id} [delete]
+func (h *Handler) deleteLabel(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err = h.services.Label.Delete(c.Request.Context(), userId, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "label not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary attachLabel
+// @Security ApiKeyAuth
+// @Tags label
+// @Description attach label to item
+// @ID attach-label
+// @Produce json
+// @Param id path int true "item id"
+// @Param label_id path int true "label id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/labels/{label_id} [post]
+func (h *Handler) attachLabel(c *gin.Context) {
+	userId, itemId, labelId, ok := itemLabelParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Label.Attach(c.Request.Context(), userId, itemId, labelId); err != nil {
+		itemLabelErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary detachLabel
+// @Security ApiKeyAuth
+// @Tags label
+// @Description detach label from item
+// @ID detach-label
+// @Produce json
+// @Param id path int true "item id"
+// @Param label_id path int true "label id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/labels/{label_id} [delete]
+func (h *Handler) detachLabel(c *gin.Context) {
+	userId, itemId, labelId, ok := itemLabelParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Label.Detach(c.Request.Context(), userId, itemId, labelId); err != nil {
+		itemLabelErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func itemLabelParams(c *gin.Context) (userId, itemId, labelId int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	itemId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return 0, 0, 0, false
+	}
+
+	labelId, err = strconv.Atoi(c.Param("label_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid label id param")
+		return 0, 0, 0, false
+	}
+
+	return userId, itemId, labelId, true
+}
+
+func itemLabelErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "item not found")
+	case errors.Is(err, model.ErrLabelNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrListArchived):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"TodoApp/internal/model"
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/gin-gonic/gin"
@@ -33,7 +34,7 @@ func (h *Handler) createList(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.TodoList.CreateList(userId, input)
+	id, err := h.services.TodoList.CreateList(c.Request.Context(), userId, input)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -44,10 +45,15 @@ func (h *Handler) createList(c *gin.Context) {
 // @Summary getAllLists
 // @Security ApiKeyAuth
 // @Tags list
-// @Description get all lists
+// @Description get all lists in the order set by the user, without archived ones by default
 // @ID get-all-lists
 // @Produce json
-// @Success 200 {integer} integer 1
+// @Param limit query int false "page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "sort column, a leading minus sorts descending" Enums(position, -position, created_at, -created_at, title, -title) default(position)
+// @Param q query string false "only lists whose title or description contains this text"
+// @Param include_archived query bool false "also return archived lists"
+// @Success 200 {object} listsPageResponse
 // @Failure 400 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/lists [get]
@@ -57,13 +63,32 @@ func (h *Handler) getAllLists(c *gin.Context) {
 		return
 	}
 
-	lists, err := h.services.TodoList.GetAll(userId)
+	page, err := parsePageQuery(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var filter model.ListFilter
+	if q := c.Query("q"); q != "" {
+		filter.Query = &q
+	}
+
+	if includeArchived := c.Query("include_archived"); includeArchived != "" {
+		filter.IncludeArchived, err = strconv.ParseBool(includeArchived)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "invalid include_archived param")
+			return
+		}
+	}
+
+	lists, next, err := h.services.TodoList.GetAll(c.Request.Context(), userId, filter, page)
+	if err != nil {
+		collectionErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{"data": lists})
+	c.JSON(http.StatusOK, listsPageResponse{Data: lists, NextCursor: next})
 }
 
 // @Summary getListById
@@ -89,7 +114,7 @@ func (h *Handler) getListById(c *gin.Context) {
 		return
 	}
 
-	list, err := h.services.TodoList.GetById(userId, id)
+	list, err := h.services.TodoList.GetById(c.Request.Context(), userId, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			newErrorResponse(c, http.StatusNotFound, "list not found")
@@ -114,6 +139,8 @@ func (h *Handler) getListById(c *gin.Context) {
 // @Param input body model.UpdateListInput true "list info"
 // @Success 200 {integer} integer 1
 // @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/lists/{id} [put]
 func (h *Handler) updateList(c *gin.Context) {
@@ -133,7 +160,15 @@ func (h *Handler) updateList(c *gin.Context) {
 		return
 	}
 
-	if err = h.services.TodoList.Update(userId, id, input); err != nil {
+	if err = h.services.TodoList.Update(c.Request.Context(), userId, id, input); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "list not found")
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -144,12 +179,14 @@ func (h *Handler) updateList(c *gin.Context) {
 // @Summary deleteList
 // @Security ApiKeyAuth
 // @Tags list
-// @Description delete list
+// @Description move list and its items to the trash
 // @ID delete-list
 // @Produce json
 // @Param id path int true "list id"
 // @Success 200 {integer} integer 1
 // @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/lists/{id} [delete]
 func (h *Handler) deleteList(c *gin.Context) {
@@ -164,8 +201,16 @@ func (h *Handler) deleteList(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoList.Delete(userId, id)
+	err = h.services.TodoList.Delete(c.Request.Context(), userId, id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "list not found")
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -174,3 +219,118 @@ func (h *Handler) deleteList(c *gin.Context) {
 		Status: "success",
 	})
 }
+
+// @Summary reorderLists
+// @Security ApiKeyAuth
+// @Tags list
+// @Description move a list right before or after another one of the user
+// @ID reorder-lists
+// @Accept json
+// @Produce json
+// @Param input body model.ReorderInput true "list id and its new neighbour"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/reorder [post]
+func (h *Handler) reorderLists(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input model.ReorderInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = h.services.TodoList.Reorder(c.Request.Context(), userId, input); err != nil {
+		reorderErrorResponse(c, err, "list not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary archiveList
+// @Security ApiKeyAuth
+// @Tags list
+// @Description archive list, its items become read-only
+// @ID archive-list
+// @Produce json
+// @Param id path int true "list id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/archive [post]
+func (h *Handler) archiveList(c *gin.Context) {
+	h.setListArchived(c, h.services.TodoList.Archive)
+}
+
+// @Summary unarchiveList
+// @Security ApiKeyAuth
+// @Tags list
+// @Description unarchive list
+// @ID unarchive-list
+// @Produce json
+// @Param id path int true "list id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/unarchive [post]
+func (h *Handler) unarchiveList(c *gin.Context) {
+	h.setListArchived(c, h.services.TodoList.Unarchive)
+}
+
+func (h *Handler) setListArchived(c *gin.Context, set func(ctx context.Context, userId, listId int) error) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err = set(c.Request.Context(), userId, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "list not found")
+			return
+		}
+		if errors.Is(err, model.ErrForbidden) {
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func reorderErrorResponse(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, notFound)
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrAnchorNotFound):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, model.ErrListArchived):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
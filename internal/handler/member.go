@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary getAllMembers
+// @Security ApiKeyAuth
+// @Tags member
+// @Description get all members of a list
+// @ID get-all-members
+// @Produce json
+// @Param id path int true "list id"
+// @Success 200 {array} model.ListMember
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/members [get]
+func (h *Handler) getAllMembers(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+		return
+	}
+
+	members, err := h.services.ListMember.GetAll(c.Request.Context(), userId, listId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"data": members})
+}
+
+// @Summary updateMember
+// @Security ApiKeyAuth
+// @Tags member
+// @Description change role of a list member, only owners may change roles
+// @ID update-member
+// @Accept json
+// @Produce json
+// @Param id path int true "list id"
+// @Param user_id path int true "member user id"
+// @Param input body model.UpdateMemberInput true "member role"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/members/{user_id} [put]
+func (h *Handler) updateMember(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+		return
+	}
+
+	memberId, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid user id param")
+		return
+	}
+
+	var input model.UpdateMemberInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = model.ValidateRole(input.Role); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = h.services.ListMember.UpdateRole(c.Request.Context(), userId, listId, memberId, input); err != nil {
+		memberErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary deleteMember
+// @Security ApiKeyAuth
+// @Tags member
+// @Description remove a member from a list, owners may remove anyone and other members may leave
+// @ID delete-member
+// @Produce json
+// @Param id path int true "list id"
+// @Param user_id path int true "member user id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/lists/{id}/members/{user_id} [delete]
+func (h *Handler) deleteMember(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+		return
+	}
+
+	memberId, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid user id param")
+		return
+	}
+
+	if err = h.services.ListMember.Delete(c.Request.Context(), userId, listId, memberId); err != nil {
+		memberErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func memberErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "member not found")
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrLastOwner):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
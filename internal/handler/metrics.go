@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"TodoApp/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests that match no route, so that scans of random
+// paths do not create a series per path.
+const unmatchedRoute = "unmatched"
+
+// httpMetrics records every request by its route template, such as
+// /api/lists/:id, rather than by its path.
+func httpMetrics(c *gin.Context) {
+	start := time.Now()
+	metrics.HTTPInFlight.Inc()
+	defer metrics.HTTPInFlight.Dec()
+
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+
+	status := strconv.Itoa(c.Writer.Status())
+	metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+	metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+}
@@ -1,14 +1,18 @@
 package handler
 
 import (
+	"TodoApp/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
 	authorizationHeader = "Authorization"
 	userCtx             = "userId"
+	tokenCtx            = "personalAccessToken"
 )
 
 func (h *Handler) userIdentity(c *gin.Context) {
@@ -24,7 +28,12 @@ func (h *Handler) userIdentity(c *gin.Context) {
 		return
 	}
 
-	userId, err := h.services.ParseToken(headerParts[1])
+	if strings.HasPrefix(headerParts[1], service.PersonalAccessTokenPrefix) {
+		h.tokenIdentity(c, headerParts[1])
+		return
+	}
+
+	userId, err := h.services.ParseToken(c.Request.Context(), headerParts[1])
 	if err != nil {
 		newErrorResponse(c, http.StatusUnauthorized, "Invalid authorization header")
 		return
@@ -32,3 +41,46 @@ func (h *Handler) userIdentity(c *gin.Context) {
 
 	c.Set(userCtx, userId)
 }
+
+func (h *Handler) tokenIdentity(c *gin.Context, token string) {
+	pat, err := h.services.Token.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, "Invalid authorization header")
+		return
+	}
+
+	if !pat.Allows(c.Request.Method) {
+		newErrorResponse(c, http.StatusForbidden, "token scope does not allow this request")
+		return
+	}
+
+	c.Set(userCtx, pat.UserId)
+	c.Set(tokenCtx, pat.Id)
+}
+
+// sessionOnly rejects requests authenticated with a personal access token, so
+// a leaked token can not be used to mint more tokens.
+func (h *Handler) sessionOnly(c *gin.Context) {
+	if _, ok := c.Get(tokenCtx); ok {
+		newErrorResponse(c, http.StatusForbidden, "personal access tokens can not be used here")
+		return
+	}
+}
+
+// transferDeadline replaces the read and write deadlines of the server for a
+// request that transfers the contents of an attachment, which take longer
+// than the server timeouts allow on ordinary connections.
+func (h *Handler) transferDeadline(c *gin.Context) {
+	var deadline time.Time
+	if h.transferTimeout > 0 {
+		deadline = time.Now().Add(h.transferTimeout)
+	}
+
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		logrus.Warnf("error setting read deadline: %s", err.Error())
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		logrus.Warnf("error setting write deadline: %s", err.Error())
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type listsPageResponse struct {
+	Data       []model.TodoList `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type itemsPageResponse struct {
+	Data       []model.TodoItem `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type activityPageResponse struct {
+	Data       []model.Activity `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+func parsePageQuery(c *gin.Context) (model.PageQuery, error) {
+	page := model.PageQuery{
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil || page.Limit == 0 {
+			return page, errors.New("invalid limit param")
+		}
+	}
+
+	return page, page.Validate()
+}
+
+// collectionErrorResponse answers a failed request for a page of a
+// collection.
+func collectionErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, model.ErrInvalidSort) || errors.Is(err, model.ErrInvalidCursor) {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
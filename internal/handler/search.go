@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type searchResponse struct {
+	Data []model.SearchResult `json:"data"`
+}
+
+// @Summary search
+// @Security ApiKeyAuth
+// @Tags search
+// @Description search titles and descriptions of all accessible lists and items, best matches first
+// @ID search
+// @Produce json
+// @Param q query string true "words, \"quoted phrases\", or and -excluded words"
+// @Param limit query int false "number of results, 20 by default" minimum(1) maximum(100)
+// @Success 200 {object} searchResponse
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/search [get]
+func (h *Handler) search(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	query := model.SearchQuery{Query: c.Query("q")}
+	if limit := c.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit == 0 {
+			newErrorResponse(c, http.StatusBadRequest, "invalid limit param")
+			return
+		}
+	}
+
+	if err = query.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := h.services.Search.Search(c.Request.Context(), userId, query)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, searchResponse{Data: results})
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary createSubtask
+// @Security ApiKeyAuth
+// @Tags subtask
+// @Description add subtask to the end of the item checklist
+// @ID create-subtask
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param input body model.Subtask true "subtask info"
+// @Success 200 {integer} integer 1
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/subtasks [post]
+func (h *Handler) createSubtask(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	var input model.Subtask
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.Subtask.Create(c.Request.Context(), userId, itemId, input)
+	if err != nil {
+		subtaskErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"id": id})
+}
+
+// @Summary getAllSubtasks
+// @Security ApiKeyAuth
+// @Tags subtask
+// @Description get subtasks of an item in checklist order
+// @ID get-all-subtasks
+// @Produce json
+// @Param id path int true "item id"
+// @Success 200 {array} model.Subtask
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/subtasks [get]
+func (h *Handler) getAllSubtasks(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	subtasks, err := h.services.Subtask.GetAll(c.Request.Context(), userId, itemId)
+	if err != nil {
+		subtaskErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"data": subtasks})
+}
+
+// @Summary updateSubtask
+// @Security ApiKeyAuth
+// @Tags subtask
+// @Description rename or toggle subtask, checking off the last open subtask completes the item
+// @ID update-subtask
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param subtask_id path int true "subtask id"
+// @Param input body model.UpdateSubtaskInput true "subtask info"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/subtasks/{subtask_id} [put]
+func (h *Handler) updateSubtask(c *gin.Context) {
+	userId, itemId, subtaskId, ok := subtaskParams(c)
+	if !ok {
+		return
+	}
+
+	var input model.UpdateSubtaskInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.Subtask.Update(c.Request.Context(), userId, itemId, subtaskId, input); err != nil {
+		subtaskErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary deleteSubtask
+// @Security ApiKeyAuth
+// @Tags subtask
+// @Description delete subtask
+// @ID delete-subtask
+// @Produce json
+// @Param id path int true "item id"
+// @Param subtask_id path int true "subtask id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/subtasks/{subtask_id} [delete]
+func (h *Handler) deleteSubtask(c *gin.Context) {
+	userId, itemId, subtaskId, ok := subtaskParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Subtask.Delete(c.Request.Context(), userId, itemId, subtaskId); err != nil {
+		subtaskErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary reorderSubtasks
+// @Security ApiKeyAuth
+// @Tags subtask
+// @Description set the checklist order of all subtasks of an item
+// @ID reorder-subtasks
+// @Accept json
+// @Produce json
+// @Param id path int true "item id"
+// @Param input body model.ReorderSubtasksInput true "subtask ids in the new order"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/items/{id}/subtasks/reorder [post]
+func (h *Handler) reorderSubtasks(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return
+	}
+
+	var input model.ReorderSubtasksInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = h.services.Subtask.Reorder(c.Request.Context(), userId, itemId, input); err != nil {
+		subtaskErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func subtaskParams(c *gin.Context) (userId, itemId, subtaskId int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	itemId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid item id param")
+		return 0, 0, 0, false
+	}
+
+	subtaskId, err = strconv.Atoi(c.Param("subtask_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid subtask id param")
+		return 0, 0, 0, false
+	}
+
+	return userId, itemId, subtaskId, true
+}
+
+func subtaskErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "item or subtask not found")
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrListArchived):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, model.ErrSubtaskOrder):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// @Summary createToken
+// @Security ApiKeyAuth
+// @Tags token
+// @Description create personal access token, the token is only shown once
+// @ID create-token
+// @Accept json
+// @Produce json
+// @Param input body model.CreateTokenInput true "token info"
+// @Success 200 {object} model.CreatedToken
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/tokens [post]
+func (h *Handler) createToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input model.CreateTokenInput
+	if err = c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err = input.Validate(); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.services.Token.Create(c.Request.Context(), userId, input)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// @Summary getAllTokens
+// @Security ApiKeyAuth
+// @Tags token
+// @Description get all active personal access tokens
+// @ID get-all-tokens
+// @Produce json
+// @Success 200 {array} model.PersonalAccessToken
+// @Failure 500 {object} errorResponse
+// @Router /api/tokens [get]
+func (h *Handler) getAllTokens(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	tokens, err := h.services.Token.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"data": tokens})
+}
+
+// @Summary revokeToken
+// @Security ApiKeyAuth
+// @Tags token
+// @Description revoke personal access token
+// @ID revoke-token
+// @Produce json
+// @Param id path int true "token id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/tokens/{id} [delete]
+func (h *Handler) revokeToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err = h.services.Token.Revoke(c.Request.Context(), userId, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			newErrorResponse(c, http.StatusNotFound, "token not found")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
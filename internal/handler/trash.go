@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"TodoApp/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type trashResponse struct {
+	Data []model.TrashEntry `json:"data"`
+}
+
+// @Summary getTrash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description get the deleted lists the user owns and the deleted items the user may change, the last deleted first
+// @ID get-trash
+// @Produce json
+// @Success 200 {object} trashResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/trash [get]
+func (h *Handler) getTrash(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	entries, err := h.services.Trash.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, trashResponse{Data: entries})
+}
+
+// @Summary restoreFromTrash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description restore a list with the items deleted with it, or a single item
+// @ID restore-from-trash
+// @Produce json
+// @Param type path string true "entry type" Enums(list, item)
+// @Param id path int true "list or item id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/trash/{type}/{id}/restore [post]
+func (h *Handler) restoreFromTrash(c *gin.Context) {
+	userId, entryType, id, ok := trashParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Trash.Restore(c.Request.Context(), userId, entryType, id); err != nil {
+		trashErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+// @Summary purgeFromTrash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description delete a list with all its items, or a single item, from the trash for good
+// @ID purge-from-trash
+// @Produce json
+// @Param type path string true "entry type" Enums(list, item)
+// @Param id path int true "list or item id"
+// @Success 200 {object} statusResponse
+// @Failure 400 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/trash/{type}/{id} [delete]
+func (h *Handler) purgeFromTrash(c *gin.Context) {
+	userId, entryType, id, ok := trashParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Trash.Purge(c.Request.Context(), userId, entryType, id); err != nil {
+		trashErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{Status: "success"})
+}
+
+func trashParams(c *gin.Context) (userId int, entryType string, id int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, "", 0, false
+	}
+
+	entryType = c.Param("type")
+	if err = model.ValidateTrashType(entryType); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid type param")
+		return 0, "", 0, false
+	}
+
+	id, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return 0, "", 0, false
+	}
+
+	return userId, entryType, id, true
+}
+
+func trashErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		newErrorResponse(c, http.StatusNotFound, "not found in trash")
+	case errors.Is(err, model.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, model.ErrListInTrash):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,113 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	annotationUp   = "-- +goose Up"
+	annotationDown = "-- +goose Down"
+)
+
+// Migration is a goose SQL migration: a file named <version>_<name>.sql with
+// the statements to apply it after a "-- +goose Up" line and the statements to
+// roll it back after a "-- +goose Down" line.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads the migrations in the root of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	versions := make(map[int64]string, len(files))
+	for _, file := range files {
+		migration, err := parseMigration(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		if other, ok := versions[migration.Version]; ok {
+			return nil, fmt.Errorf("%s: version %d is already used by %s", file, migration.Version, other)
+		}
+		versions[migration.Version] = file
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func parseMigration(fsys fs.FS, file string) (Migration, error) {
+	var migration Migration
+
+	versionPart, name, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".sql"), "_")
+	if !ok {
+		return migration, fmt.Errorf("file name must be <version>_<name>.sql")
+	}
+
+	version, err := strconv.ParseInt(versionPart, 10, 64)
+	if err != nil || version <= 0 {
+		return migration, fmt.Errorf("invalid version %q", versionPart)
+	}
+	migration.Version = version
+	migration.Name = name
+
+	f, err := fsys.Open(file)
+	if err != nil {
+		return migration, err
+	}
+	defer f.Close()
+
+	var up, down strings.Builder
+	var section *strings.Builder
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch strings.TrimSpace(line) {
+		case annotationUp:
+			section = &up
+			continue
+		case annotationDown:
+			section = &down
+			continue
+		}
+
+		if section == nil {
+			if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return migration, fmt.Errorf("statements before %q", annotationUp)
+			}
+			continue
+		}
+		section.WriteString(line)
+		section.WriteByte('\n')
+	}
+	if err = scanner.Err(); err != nil {
+		return migration, err
+	}
+
+	if section == nil {
+		return migration, fmt.Errorf("missing %q", annotationUp)
+	}
+	migration.Up = up.String()
+	migration.Down = down.String()
+
+	return migration, nil
+}
@@ -0,0 +1,248 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// versionTable is the table goose keeps applied versions in, so databases
+// migrated with the goose CLI keep working.
+const versionTable = "goose_db_version"
+
+// lockId is the key of the advisory lock that keeps replicas starting at the
+// same time from migrating concurrently.
+const lockId int64 = 7134209861
+
+var (
+	ErrNoApplied      = errors.New("no applied migrations to roll back")
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// Status is a migration with the time it was applied at, nil if it is pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sqlx.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sqlx.Conn, applied map[int64]time.Time) error {
+		return m.upTo(ctx, conn, applied, m.latest())
+	})
+}
+
+// Down rolls back the latest applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sqlx.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return m.apply(ctx, conn, m.migrations[i], false)
+			}
+		}
+		return ErrNoApplied
+	})
+}
+
+// To applies or rolls back migrations until the given version is the latest
+// applied one. Version 0 rolls back everything.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && !m.has(version) {
+		return fmt.Errorf("%w %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *sqlx.Conn, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && m.migrations[i].Version > version; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				if err := m.apply(ctx, conn, m.migrations[i], false); err != nil {
+					return err
+				}
+			}
+		}
+
+		return m.upTo(ctx, conn, applied, version)
+	})
+}
+
+// Status returns every known migration with the time it was applied at.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses := make([]Status, len(m.migrations))
+	err := m.withLock(ctx, func(_ *sqlx.Conn, applied map[int64]time.Time) error {
+		for i, migration := range m.migrations {
+			statuses[i].Migration = migration
+			if appliedAt, ok := applied[migration.Version]; ok {
+				statuses[i].AppliedAt = &appliedAt
+			}
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending returns the number of migrations that are not applied yet. Unlike
+// the other methods it neither takes the lock nor creates the version table,
+// so it is cheap enough for readiness probes.
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	var exists bool
+	if err := m.db.GetContext(ctx, &exists, "SELECT to_regclass($1) IS NOT NULL", versionTable); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return len(m.migrations), nil
+	}
+
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withLock runs fn holding the advisory lock on a single connection, as
+// session locks belong to the connection that takes them.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn, applied map[int64]time.Time) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockId); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockId); err != nil {
+			logrus.Errorf("error releasing migration lock: %s", err.Error())
+		}
+	}()
+
+	createQuery := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
+										(id SERIAL PRIMARY KEY, version_id BIGINT NOT NULL, is_applied BOOLEAN NOT NULL, tstamp TIMESTAMP DEFAULT NOW())`,
+		versionTable)
+	if _, err = conn.ExecContext(ctx, createQuery); err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+// upTo applies the pending migrations up to the given version.
+func (m *Migrator) upTo(ctx context.Context, conn *sqlx.Conn, applied map[int64]time.Time, version int64) error {
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.apply(ctx, conn, migration, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs the up or down statements of a migration and records it in one
+// transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration, up bool) error {
+	statements, action := migration.Up, "applied"
+	versionQuery := fmt.Sprintf("INSERT INTO %s (version_id, is_applied) VALUES ($1, TRUE)", versionTable)
+	if !up {
+		statements, action = migration.Down, "rolled back"
+		versionQuery = fmt.Sprintf("DELETE FROM %s WHERE version_id = $1", versionTable)
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, statements); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, versionQuery, migration.Version); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	logrus.Infof("%s migration %d_%s", action, migration.Version, migration.Name)
+	return nil
+}
+
+// appliedVersions reads the applied versions and when they were applied. Older
+// goose versions record rollbacks as rows that are not applied, so only the
+// latest row of every version counts.
+func appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[int64]time.Time, error) {
+	var rows []struct {
+		Version   int64        `db:"version_id"`
+		IsApplied bool         `db:"is_applied"`
+		AppliedAt sql.NullTime `db:"tstamp"`
+	}
+	query := fmt.Sprintf("SELECT DISTINCT ON (version_id) version_id, is_applied, tstamp FROM %s ORDER BY version_id, id DESC", versionTable)
+	if err := conn.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		if row.IsApplied && row.Version > 0 {
+			applied[row.Version] = row.AppliedAt.Time
+		}
+	}
+	return applied, nil
+}
+
+func (m *Migrator) latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) has(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
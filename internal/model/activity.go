@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	EntityList = "list"
+	EntityItem = "item"
+)
+
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+	ActionMoved    = "moved"
+	ActionInvited  = "invited"
+	ActionShared   = "shared"
+	ActionUnshared = "unshared"
+)
+
+// Activity is an entry of the history of a list or an item. ListId is the
+// list the entity belonged to at the time, the target list for moves.
+type Activity struct {
+	Id            int       `json:"id" db:"id"`
+	ActorId       *int      `json:"actor_id" db:"actor_id"`
+	ActorUsername *string   `json:"actor_username" db:"actor_username"`
+	EntityType    string    `json:"entity_type" db:"entity_type"`
+	EntityId      int       `json:"entity_id" db:"entity_id"`
+	ListId        int       `json:"list_id" db:"list_id"`
+	Action        string    `json:"action" db:"action"`
+	Changes       Changes   `json:"changes,omitempty" db:"changes"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Changes maps the JSON names of changed fields to their old and new values.
+// Sharing changes the role of a member, its key is MemberField of the member.
+type Changes map[string]FieldChange
+
+// MemberField is the key of the role of a member in Changes.
+func MemberField(userId int) string {
+	return "member:" + strconv.Itoa(userId)
+}
+
+// Scan reads the changes from a JSON column, which is null for entries
+// without changes.
+func (c *Changes) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(data, c)
+	case string:
+		return json.Unmarshal([]byte(data), c)
+	default:
+		return fmt.Errorf("can not scan %T into changes", src)
+	}
+}
+
+// Changes returns the fields the input sets to a new value.
+func (u UpdateListInput) Changes(old TodoList) Changes {
+	changes := make(Changes)
+
+	if u.Title != nil && *u.Title != old.Title {
+		changes["title"] = FieldChange{Old: old.Title, New: *u.Title}
+	}
+
+	if u.Description != nil && *u.Description != old.Description {
+		changes["description"] = FieldChange{Old: old.Description, New: *u.Description}
+	}
+
+	return changes
+}
+
+// Changes returns the fields the input sets to a new value.
+func (u UpdateItemInput) Changes(old TodoItem) Changes {
+	changes := make(Changes)
+
+	if u.Title != nil && *u.Title != old.Title {
+		changes["title"] = FieldChange{Old: old.Title, New: *u.Title}
+	}
+
+	if u.Description != nil && *u.Description != old.Description {
+		changes["description"] = FieldChange{Old: old.Description, New: *u.Description}
+	}
+
+	if u.Done != nil && *u.Done != old.Done {
+		changes["done"] = FieldChange{Old: old.Done, New: *u.Done}
+	}
+
+	if u.Priority != nil && *u.Priority != old.Priority {
+		changes["priority"] = FieldChange{Old: old.Priority, New: *u.Priority}
+	}
+
+	if u.StartAt.Set && !equalTimes(u.StartAt.Time, old.StartAt) {
+		changes["start_at"] = FieldChange{Old: old.StartAt, New: u.StartAt.Time}
+	}
+
+	if u.DueAt.Set && !equalTimes(u.DueAt.Time, old.DueAt) {
+		changes["due_at"] = FieldChange{Old: old.DueAt, New: u.DueAt.Time}
+	}
+
+	if u.Recurrence.Set && !equalStrings(u.Recurrence.String, old.Recurrence) {
+		changes["recurrence"] = FieldChange{Old: old.Recurrence, New: u.Recurrence.String}
+	}
+
+	return changes
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const maxAttachmentNameLength = 255
+
+// Attachment is the metadata of a file attached to an item. The contents are
+// kept in a blob store under BlobKey.
+type Attachment struct {
+	Id          int       `json:"id" db:"id"`
+	ItemId      int       `json:"item_id" db:"item_id"`
+	UploaderId  *int      `json:"uploader_id" db:"uploader_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	BlobKey     string    `json:"-" db:"blob_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+func (a Attachment) Validate() error {
+	if strings.TrimSpace(a.Filename) == "" {
+		return errors.New("filename must not be empty")
+	}
+
+	if len(a.Filename) > maxAttachmentNameLength {
+		return fmt.Errorf("filename must not be longer than %d bytes", maxAttachmentNameLength)
+	}
+
+	if a.ContentType == "" || len(a.ContentType) > maxAttachmentNameLength {
+		return errors.New("invalid content type")
+	}
+
+	return nil
+}
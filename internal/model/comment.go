@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxCommentLength = 10000
+
+// mentionRegexp matches @username at the start of the body or after a
+// character that can not be part of an email address. A trailing dot or dash
+// ends the sentence rather than the username.
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@.-])@([\w.-]*\w)`)
+
+type Comment struct {
+	Id             int     `json:"id" db:"id"`
+	ItemId         int     `json:"item_id" db:"item_id"`
+	AuthorId       *int    `json:"author_id" db:"author_id"`
+	AuthorUsername *string `json:"author_username" db:"author_username"`
+	Body           string  `json:"body" db:"body" binding:"required"`
+	// Mentions are the members of the list mentioned as @username in the body.
+	Mentions  []string  `json:"mentions" db:"-"`
+	Edited    bool      `json:"edited" db:"edited"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (c Comment) Validate() error {
+	return validateCommentBody(c.Body)
+}
+
+type UpdateCommentInput struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (u UpdateCommentInput) Validate() error {
+	return validateCommentBody(u.Body)
+}
+
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body must not be empty")
+	}
+
+	if len(body) > maxCommentLength {
+		return fmt.Errorf("body must not be longer than %d bytes", maxCommentLength)
+	}
+
+	return nil
+}
+
+// ParseMentions returns the usernames mentioned in a comment body, each once,
+// in the order they first appear.
+func ParseMentions(body string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionRegexp.FindAllStringSubmatch(body, -1) {
+		if username := match[1]; !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
@@ -0,0 +1,33 @@
+package model
+
+import "errors"
+
+var (
+	ErrForbidden     = errors.New("not enough permissions for this list")
+	ErrLastOwner     = errors.New("list must keep at least one owner")
+	ErrAlreadyMember = errors.New("user is already a member of the list")
+	ErrUserNotFound  = errors.New("user not found")
+
+	ErrAlreadyInvited    = errors.New("user already has a pending invitation to the list")
+	ErrInvitationExpired = errors.New("invitation has expired")
+
+	ErrLabelExists   = errors.New("label with this name already exists")
+	ErrLabelNotFound = errors.New("label not found")
+
+	ErrSubtaskOrder = errors.New("ids must list every subtask of the item exactly once")
+
+	ErrNotCommentAuthor = errors.New("only the author may edit the comment")
+
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+	ErrAnchorNotFound = errors.New("before_id or after_id not found")
+
+	ErrFilterExists = errors.New("filter with this name already exists")
+
+	ErrListInTrash = errors.New("list of the item is in the trash, restore the list first")
+
+	ErrListArchived = errors.New("list is archived, unarchive it to change its items")
+
+	ErrInvalidSort   = errors.New("unsupported sort param")
+	ErrInvalidCursor = errors.New("invalid cursor param")
+)
@@ -0,0 +1,290 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Kinds of the item fields a filter can compare. The kind decides the
+// operators and values allowed.
+const (
+	FieldText     = "text"
+	FieldPriority = "priority"
+	FieldBool     = "bool"
+	FieldInt      = "int"
+	FieldTime     = "time"
+)
+
+const (
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpContains = "contains"
+	OpIn       = "in"
+	OpIsNull   = "is_null"
+)
+
+// FilterFields lists the item fields filters can compare by kind. A new field
+// needs an entry here and a column in the repository.
+var FilterFields = map[string]string{
+	"title":        FieldText,
+	"description":  FieldText,
+	"priority":     FieldPriority,
+	"done":         FieldBool,
+	"list_id":      FieldInt,
+	"label_id":     FieldInt,
+	"start_at":     FieldTime,
+	"due_at":       FieldTime,
+	"completed_at": FieldTime,
+	"created_at":   FieldTime,
+	"updated_at":   FieldTime,
+}
+
+var filterOps = map[string][]string{
+	FieldText:     {OpEq, OpNe, OpContains, OpIn},
+	FieldPriority: {OpEq, OpNe, OpIn},
+	FieldBool:     {OpEq},
+	FieldInt:      {OpEq, OpNe, OpIn},
+	FieldTime:     {OpLt, OpLte, OpGt, OpGte, OpIsNull},
+}
+
+const (
+	maxFilterDepth  = 8
+	maxFilterNodes  = 64
+	maxFilterValues = 100
+)
+
+// relativeTime is a time relative to the moment a filter runs, like "now",
+// "now+7d" or "now-12h".
+var relativeTime = regexp.MustCompile(`^now(?:([+-])(\d{1,4})([mhdw]))?$`)
+
+// FilterExpr is a boolean expression over items. A node either combines other
+// nodes with and, or and not, or compares a field with a value, e.g.
+// {"and": [{"field": "done", "op": "eq", "value": false},
+// {"field": "title", "op": "contains", "value": "invoice"}]}.
+type FilterExpr struct {
+	And   []FilterExpr    `json:"and,omitempty"`
+	Or    []FilterExpr    `json:"or,omitempty"`
+	Not   *FilterExpr     `json:"not,omitempty"`
+	Field string          `json:"field,omitempty"`
+	Op    string          `json:"op,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+func (e FilterExpr) Validate() error {
+	nodes := 0
+	return e.validate(1, &nodes)
+}
+
+func (e FilterExpr) validate(depth int, nodes *int) error {
+	if depth > maxFilterDepth {
+		return fmt.Errorf("filter must not be nested deeper than %d levels", maxFilterDepth)
+	}
+
+	*nodes++
+	if *nodes > maxFilterNodes {
+		return fmt.Errorf("filter must not have more than %d nodes", maxFilterNodes)
+	}
+
+	set := 0
+	for _, ok := range []bool{len(e.And) > 0, len(e.Or) > 0, e.Not != nil, e.Field != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("filter node must have exactly one of and, or, not or field")
+	}
+
+	for _, children := range [][]FilterExpr{e.And, e.Or} {
+		for _, child := range children {
+			if err := child.validate(depth+1, nodes); err != nil {
+				return err
+			}
+		}
+	}
+
+	if e.Not != nil {
+		return e.Not.validate(depth+1, nodes)
+	}
+
+	if e.Field != "" {
+		_, err := e.Values(time.Now())
+		return err
+	}
+
+	return nil
+}
+
+// Values checks a comparison and decodes its value by the kind of the field:
+// strings, bools, ints or times relative to now. Only in has several values,
+// is_null has a bool telling whether the field must be null.
+func (e FilterExpr) Values(now time.Time) ([]interface{}, error) {
+	kind, ok := FilterFields[e.Field]
+	if !ok {
+		return nil, fmt.Errorf("filter field %s is not supported", e.Field)
+	}
+
+	if !containsOp(filterOps[kind], e.Op) {
+		return nil, fmt.Errorf("filter op %s is not supported for %s", e.Op, e.Field)
+	}
+
+	if len(e.Value) == 0 {
+		return nil, fmt.Errorf("filter value for %s must be set", e.Field)
+	}
+
+	if e.Op == OpIsNull {
+		var isNull bool
+		if err := json.Unmarshal(e.Value, &isNull); err != nil {
+			return nil, fmt.Errorf("filter value of is_null for %s must be a bool", e.Field)
+		}
+		return []interface{}{isNull}, nil
+	}
+
+	raw := []json.RawMessage{e.Value}
+	if e.Op == OpIn {
+		raw = nil
+		if err := json.Unmarshal(e.Value, &raw); err != nil || len(raw) == 0 || len(raw) > maxFilterValues {
+			return nil, fmt.Errorf("filter value of in for %s must be an array of 1 to %d values", e.Field, maxFilterValues)
+		}
+	}
+
+	values := make([]interface{}, len(raw))
+	for i, r := range raw {
+		value, err := decodeFilterValue(kind, r, now)
+		if err != nil {
+			return nil, fmt.Errorf("filter value for %s: %w", e.Field, err)
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func decodeFilterValue(kind string, raw json.RawMessage, now time.Time) (interface{}, error) {
+	switch kind {
+	case FieldBool:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, errors.New("must be a bool")
+		}
+		return b, nil
+	case FieldInt:
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return n, nil
+	case FieldTime:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, errors.New("must be an RFC 3339 time or a relative time like now+7d")
+		}
+		return parseFilterTime(s, now)
+	default:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, errors.New("must be a string")
+		}
+		if kind == FieldPriority {
+			if err := ValidatePriority(s); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	}
+}
+
+func parseFilterTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	m := relativeTime.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, errors.New("must be an RFC 3339 time or a relative time like now+7d")
+	}
+
+	if m[1] == "" {
+		return now, nil
+	}
+
+	n, _ := strconv.Atoi(m[2])
+	if m[1] == "-" {
+		n = -n
+	}
+
+	switch m[3] {
+	case "m":
+		return now.Add(time.Duration(n) * time.Minute), nil
+	case "h":
+		return now.Add(time.Duration(n) * time.Hour), nil
+	case "d":
+		return now.AddDate(0, 0, n), nil
+	default:
+		return now.AddDate(0, 0, 7*n), nil
+	}
+}
+
+func containsOp(ops []string, op string) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan reads the expression from a JSON column.
+func (e *FilterExpr) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, e)
+	case string:
+		return json.Unmarshal([]byte(data), e)
+	default:
+		return fmt.Errorf("can not scan %T into a filter expression", src)
+	}
+}
+
+// SavedFilter is a named filter that works like a list of all matching items
+// across the lists of the user.
+type SavedFilter struct {
+	Id         int        `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name" binding:"required"`
+	Expression FilterExpr `json:"expression" db:"expression"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+func (f SavedFilter) Validate() error {
+	return f.Expression.Validate()
+}
+
+type UpdateFilterInput struct {
+	Name       *string     `json:"name"`
+	Expression *FilterExpr `json:"expression"`
+}
+
+func (u UpdateFilterInput) Validate() error {
+	if u.Name == nil && u.Expression == nil {
+		return errors.New("either name or expression must be set")
+	}
+
+	if u.Name != nil && *u.Name == "" {
+		return errors.New("name must not be empty")
+	}
+
+	if u.Expression != nil {
+		return u.Expression.Validate()
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterExprValidate(t *testing.T) {
+	valid := []string{
+		`{"field": "title", "op": "eq", "value": "report"}`,
+		`{"field": "title", "op": "ne", "value": "report"}`,
+		`{"field": "title", "op": "contains", "value": "rep"}`,
+		`{"field": "description", "op": "in", "value": ["a", "b"]}`,
+		`{"field": "priority", "op": "eq", "value": "high"}`,
+		`{"field": "priority", "op": "ne", "value": "none"}`,
+		`{"field": "priority", "op": "in", "value": ["low", "medium"]}`,
+		`{"field": "done", "op": "eq", "value": true}`,
+		`{"field": "list_id", "op": "eq", "value": 1}`,
+		`{"field": "list_id", "op": "ne", "value": 1}`,
+		`{"field": "label_id", "op": "in", "value": [1, 2]}`,
+		`{"field": "start_at", "op": "lt", "value": "2024-05-01T00:00:00Z"}`,
+		`{"field": "due_at", "op": "lte", "value": "now+7d"}`,
+		`{"field": "completed_at", "op": "gt", "value": "now-12h"}`,
+		`{"field": "created_at", "op": "gte", "value": "now"}`,
+		`{"field": "updated_at", "op": "is_null", "value": false}`,
+		`{"not": {"field": "done", "op": "eq", "value": true}}`,
+		`{"and": [{"field": "done", "op": "eq", "value": false}, {"or": [{"field": "due_at", "op": "is_null", "value": true}, {"field": "due_at", "op": "lt", "value": "now+1w"}]}]}`,
+		nested(maxFilterDepth),
+		wide(maxFilterNodes - 1),
+	}
+
+	for _, input := range valid {
+		t.Run(short(input), func(t *testing.T) {
+			if err := mustFilter(t, input).Validate(); err != nil {
+				t.Errorf("Validate() error: %v", err)
+			}
+		})
+	}
+
+	invalid := []string{
+		`{}`,
+		`{"field": "title", "op": "eq", "value": "a", "not": {"field": "done", "op": "eq", "value": true}}`,
+		`{"and": [{"field": "done", "op": "eq", "value": true}], "or": [{"field": "done", "op": "eq", "value": true}]}`,
+		`{"field": "owner", "op": "eq", "value": "me"}`,
+		`{"field": "title", "op": "like", "value": "a"}`,
+		`{"field": "title", "op": "lt", "value": "a"}`,
+		`{"field": "priority", "op": "contains", "value": "hi"}`,
+		`{"field": "done", "op": "ne", "value": true}`,
+		`{"field": "list_id", "op": "gt", "value": 1}`,
+		`{"field": "due_at", "op": "eq", "value": "now"}`,
+		`{"field": "title", "op": "eq"}`,
+		`{"field": "title", "op": "eq", "value": 1}`,
+		`{"field": "priority", "op": "eq", "value": "critical"}`,
+		`{"field": "priority", "op": "in", "value": ["low", "critical"]}`,
+		`{"field": "done", "op": "eq", "value": "yes"}`,
+		`{"field": "list_id", "op": "eq", "value": "1"}`,
+		`{"field": "list_id", "op": "in", "value": []}`,
+		`{"field": "list_id", "op": "in", "value": 1}`,
+		`{"field": "list_id", "op": "in", "value": [` + strings.Repeat("1, ", maxFilterValues) + `1]}`,
+		`{"field": "due_at", "op": "lt", "value": "tomorrow"}`,
+		`{"field": "due_at", "op": "lt", "value": "now+7y"}`,
+		`{"field": "due_at", "op": "lt", "value": "now+99999d"}`,
+		`{"field": "due_at", "op": "is_null", "value": "yes"}`,
+		`{"not": {"field": "owner", "op": "eq", "value": "me"}}`,
+		`{"or": [{"field": "done", "op": "eq", "value": true}, {}]}`,
+		nested(maxFilterDepth + 1),
+		wide(maxFilterNodes),
+	}
+
+	for _, input := range invalid {
+		t.Run(short(input), func(t *testing.T) {
+			if err := mustFilter(t, input).Validate(); err == nil {
+				t.Errorf("Validate() of %s succeeded, want an error", input)
+			}
+		})
+	}
+}
+
+func TestFilterExprValues(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  []interface{}
+	}{
+		{`{"field": "title", "op": "contains", "value": "50%"}`, []interface{}{"50%"}},
+		{`{"field": "priority", "op": "in", "value": ["low", "high"]}`, []interface{}{"low", "high"}},
+		{`{"field": "done", "op": "eq", "value": false}`, []interface{}{false}},
+		{`{"field": "label_id", "op": "in", "value": [3, 1]}`, []interface{}{3, 1}},
+		{`{"field": "due_at", "op": "is_null", "value": true}`, []interface{}{true}},
+		{`{"field": "due_at", "op": "lt", "value": "2024-06-01T08:00:00+02:00"}`, []interface{}{time.Date(2024, time.June, 1, 6, 0, 0, 0, time.UTC)}},
+		{`{"field": "due_at", "op": "lt", "value": "now"}`, []interface{}{now}},
+		{`{"field": "due_at", "op": "lt", "value": "now+30m"}`, []interface{}{now.Add(30 * time.Minute)}},
+		{`{"field": "due_at", "op": "lt", "value": "now-12h"}`, []interface{}{now.Add(-12 * time.Hour)}},
+		{`{"field": "due_at", "op": "lt", "value": "now+7d"}`, []interface{}{time.Date(2024, time.May, 17, 12, 30, 0, 0, time.UTC)}},
+		{`{"field": "due_at", "op": "lt", "value": "now-2w"}`, []interface{}{time.Date(2024, time.April, 26, 12, 30, 0, 0, time.UTC)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(short(tt.input), func(t *testing.T) {
+			got, err := mustFilter(t, tt.input).Values(now)
+			if err != nil {
+				t.Fatalf("Values() error: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Values() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if gotTime, ok := got[i].(time.Time); ok {
+					if !gotTime.Equal(tt.want[i].(time.Time)) {
+						t.Errorf("Values()[%d] = %v, want %v", i, got[i], tt.want[i])
+					}
+				} else if got[i] != tt.want[i] {
+					t.Errorf("Values()[%d] = %#v, want %#v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func mustFilter(t *testing.T, input string) FilterExpr {
+	t.Helper()
+
+	var expr FilterExpr
+	if err := json.Unmarshal([]byte(input), &expr); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %v", input, err)
+	}
+	return expr
+}
+
+// nested returns a filter with the given number of levels.
+func nested(depth int) string {
+	s := `{"field": "done", "op": "eq", "value": true}`
+	for i := 1; i < depth; i++ {
+		s = `{"not": ` + s + `}`
+	}
+	return s
+}
+
+// wide returns a filter with an and node over the given number of children.
+func wide(children int) string {
+	conditions := make([]string, children)
+	for i := range conditions {
+		conditions[i] = fmt.Sprintf(`{"field": "list_id", "op": "eq", "value": %d}`, i)
+	}
+	return `{"and": [` + strings.Join(conditions, ", ") + `]}`
+}
+
+func short(s string) string {
+	if len(s) > 60 {
+		return s[:60]
+	}
+	return s
+}
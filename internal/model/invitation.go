@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationExpired  = "expired"
+)
+
+type Invitation struct {
+	Id              int       `json:"id" db:"id"`
+	ListId          int       `json:"list_id" db:"list_id"`
+	ListTitle       string    `json:"list_title" db:"list_title"`
+	InviterUsername string    `json:"inviter_username" db:"inviter_username"`
+	Role            string    `json:"role" db:"role"`
+	Status          string    `json:"status" db:"status"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt       time.Time `json:"expires_at" db:"expires_at"`
+}
+
+type InviteInput struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
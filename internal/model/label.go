@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+var colorRegexp = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type Label struct {
+	Id        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name" binding:"required"`
+	Color     string    `json:"color" db:"color" binding:"required"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (l Label) Validate() error {
+	return validateColor(l.Color)
+}
+
+type UpdateLabelInput struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+func (u UpdateLabelInput) Validate() error {
+	if u.Name == nil && u.Color == nil {
+		return errors.New("either name or color must be set")
+	}
+
+	if u.Name != nil && *u.Name == "" {
+		return errors.New("name must not be empty")
+	}
+
+	if u.Color != nil {
+		return validateColor(*u.Color)
+	}
+
+	return nil
+}
+
+func validateColor(color string) error {
+	if !colorRegexp.MatchString(color) {
+		return errors.New("color must be a hex color like #1e90ff")
+	}
+
+	return nil
+}
+
+// ItemLabel is a label attached to an item.
+type ItemLabel struct {
+	ItemId int `db:"item_id"`
+	Label
+}
@@ -0,0 +1,28 @@
+package model
+
+import "errors"
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+func ValidateRole(role string) error {
+	if role != RoleOwner && role != RoleEditor && role != RoleViewer {
+		return errors.New("role must be one of owner, editor or viewer")
+	}
+
+	return nil
+}
+
+type ListMember struct {
+	UserId   int    `json:"user_id" db:"user_id"`
+	Name     string `json:"name" db:"name"`
+	Username string `json:"username" db:"username"`
+	Role     string `json:"role" db:"role"`
+}
+
+type UpdateMemberInput struct {
+	Role string `json:"role" binding:"required"`
+}
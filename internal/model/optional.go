@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OptionalTime tells a field missing from a JSON update apart from an explicit
+// null: Set is true in both of the latter cases, Time is nil for null.
+type OptionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (o *OptionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Time = nil
+		return nil
+	}
+
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	o.Time = &t
+
+	return nil
+}
+
+func (o OptionalTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Time)
+}
+
+// OptionalString is the OptionalTime counterpart for strings.
+type OptionalString struct {
+	Set    bool
+	String *string
+}
+
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.String = nil
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	o.String = &s
+
+	return nil
+}
+
+func (o OptionalString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.String)
+}
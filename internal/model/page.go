@@ -0,0 +1,25 @@
+package model
+
+import "fmt"
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// PageQuery selects a page of a collection. Sort names a column, a leading
+// minus sorts descending. Cursor is the next_cursor of the previous page and
+// must be used with the same sort.
+type PageQuery struct {
+	Limit  int
+	Cursor string
+	Sort   string
+}
+
+func (p PageQuery) Validate() error {
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	return nil
+}
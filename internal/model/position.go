@@ -0,0 +1,23 @@
+package model
+
+import "errors"
+
+// ReorderInput moves an item within its list or a list within the sidebar of
+// the user: right before BeforeId or right after AfterId.
+type ReorderInput struct {
+	Id       int  `json:"id" binding:"required"`
+	BeforeId *int `json:"before_id"`
+	AfterId  *int `json:"after_id"`
+}
+
+func (r ReorderInput) Validate() error {
+	if (r.BeforeId == nil) == (r.AfterId == nil) {
+		return errors.New("exactly one of before_id or after_id must be set")
+	}
+
+	if r.BeforeId != nil && *r.BeforeId == r.Id || r.AfterId != nil && *r.AfterId == r.Id {
+		return errors.New("can not move relative to itself")
+	}
+
+	return nil
+}
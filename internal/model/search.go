@@ -0,0 +1,50 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	SearchTypeList = "list"
+	SearchTypeItem = "item"
+
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchQuery is a web search style query: words, "quoted phrases", or and
+// -excluded words.
+type SearchQuery struct {
+	Query string
+	Limit int
+}
+
+func (q SearchQuery) Validate() error {
+	if strings.TrimSpace(q.Query) == "" {
+		return errors.New("q must be set")
+	}
+
+	if len(q.Query) > 255 {
+		return errors.New("q must be at most 255 characters long")
+	}
+
+	if q.Limit < 0 || q.Limit > MaxSearchLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxSearchLimit)
+	}
+
+	return nil
+}
+
+// SearchResult is a list or an item matching a search. Title and Snippet are
+// HTML-escaped and highlight the matched words with <mark> tags, the snippet
+// is empty for a list or an item without description.
+type SearchResult struct {
+	Type    string  `json:"type" db:"type" enums:"list,item"`
+	Id      int     `json:"id" db:"id"`
+	ListId  int     `json:"list_id" db:"list_id"`
+	Title   string  `json:"title" db:"title"`
+	Snippet string  `json:"snippet" db:"snippet"`
+	Rank    float64 `json:"rank" db:"rank"`
+}
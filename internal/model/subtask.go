@@ -0,0 +1,38 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+type Subtask struct {
+	Id        int       `json:"id" db:"id"`
+	Title     string    `json:"title" db:"title" binding:"required"`
+	Done      bool      `json:"done" db:"done"`
+	Position  int       `json:"position" db:"position"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type UpdateSubtaskInput struct {
+	Title *string `json:"title"`
+	Done  *bool   `json:"done"`
+}
+
+func (u UpdateSubtaskInput) Validate() error {
+	if u.Title == nil && u.Done == nil {
+		return errors.New("either title or done must be set")
+	}
+
+	return nil
+}
+
+type ReorderSubtasksInput struct {
+	// Ids lists every subtask of the item in the new order.
+	Ids []int `json:"ids" binding:"required"`
+}
+
+// Progress counts the subtasks of an item.
+type Progress struct {
+	Done  int `json:"done" db:"done"`
+	Total int `json:"total" db:"total"`
+}
@@ -1,24 +1,109 @@
 package model
 
-import "errors"
+import (
+	"TodoApp/internal/recurrence"
+	"errors"
+	"time"
+)
 
 type TodoList struct {
 	Id          int    `json:"id" db:"id"`
 	Title       string `json:"title" db:"title" binding:"required"`
 	Description string `json:"description" db:"description"`
+	Role        string `json:"role" db:"role"`
+	Position    int64  `json:"position" db:"position"`
+	// Archived lists are hidden from the lists of the user by default and
+	// their items are read-only.
+	Archived  bool      `json:"archived" db:"archived"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
 type UserList struct {
-	Id     int `json:"id"`
-	UserId int `json:"user_id"`
-	ListId int `json:"list_id"`
+	Id     int    `json:"id"`
+	UserId int    `json:"user_id"`
+	ListId int    `json:"list_id"`
+	Role   string `json:"role"`
+}
+
+const (
+	PriorityNone   = "none"
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+func ValidatePriority(priority string) error {
+	switch priority {
+	case PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return nil
+	default:
+		return errors.New("priority must be one of none, low, medium, high or urgent")
+	}
 }
 
 type TodoItem struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
-	Done        bool   `json:"done" db:"done"`
+	Id          int        `json:"id" db:"id"`
+	ListId      int        `json:"list_id" db:"list_id"`
+	Title       string     `json:"title" db:"title" binding:"required"`
+	Description string     `json:"description" db:"description"`
+	Done        bool       `json:"done" db:"done"`
+	Priority    string     `json:"priority" db:"priority"`
+	Position    int64      `json:"position" db:"position"`
+	Labels      []Label    `json:"labels" db:"-"`
+	Progress    Progress   `json:"progress" db:"progress"`
+	Subtasks    []Subtask  `json:"subtasks,omitempty" db:"-"`
+	StartAt     *time.Time `json:"start_at" db:"start_at"`
+	DueAt       *time.Time `json:"due_at" db:"due_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	// Recurrence is an RRULE like "FREQ=WEEKLY;BYDAY=MO". Completing the item
+	// creates its next occurrence, Occurrence counts them from 1.
+	Recurrence *string `json:"recurrence" db:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO"`
+	Occurrence int     `json:"occurrence" db:"occurrence"`
+	Recurred   bool    `json:"-" db:"recurred"`
+}
+
+func (i TodoItem) Validate() error {
+	if i.Priority != "" {
+		if err := ValidatePriority(i.Priority); err != nil {
+			return err
+		}
+	}
+
+	if i.Recurrence != nil {
+		if _, err := recurrence.Parse(*i.Recurrence); err != nil {
+			return err
+		}
+	}
+
+	return validateSchedule(i.StartAt, i.DueAt)
+}
+
+// ItemFilter narrows down the items of a list or of all lists of a user.
+type ItemFilter struct {
+	DueBefore *time.Time
+	// Overdue selects items that are not done and past their due date.
+	Overdue  bool
+	LabelId  *int
+	Priority *string
+	Done     *bool
+	// Query matches a part of the title or description, ignoring case.
+	Query *string
+}
+
+// ListFilter narrows down the lists of a user.
+type ListFilter struct {
+	// Query matches a part of the title or description, ignoring case.
+	Query *string
+	// IncludeArchived also returns archived lists.
+	IncludeArchived bool
+}
+
+// MoveItemInput names the list an item is moved or copied to.
+type MoveItemInput struct {
+	ListId int `json:"list_id" binding:"required"`
 }
 
 type ListItem struct {
@@ -44,11 +129,37 @@ type UpdateItemInput struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
 	Done        *bool   `json:"done"`
+	Priority    *string `json:"priority"`
+	// StartAt and DueAt are cleared by an explicit null.
+	StartAt OptionalTime `json:"start_at" swaggertype:"string" format:"date-time"`
+	DueAt   OptionalTime `json:"due_at" swaggertype:"string" format:"date-time"`
+	// Recurrence is cleared by an explicit null as well.
+	Recurrence OptionalString `json:"recurrence" swaggertype:"string" example:"FREQ=MONTHLY;BYMONTHDAY=1"`
 }
 
 func (u UpdateItemInput) Validate() error {
-	if u.Title == nil && u.Description == nil && u.Done == nil {
-		return errors.New("either title, description or done must be set")
+	if u.Title == nil && u.Description == nil && u.Done == nil && u.Priority == nil && !u.StartAt.Set && !u.DueAt.Set && !u.Recurrence.Set {
+		return errors.New("either title, description, done, priority, start_at, due_at or recurrence must be set")
+	}
+
+	if u.Priority != nil {
+		if err := ValidatePriority(*u.Priority); err != nil {
+			return err
+		}
+	}
+
+	if u.Recurrence.String != nil {
+		if _, err := recurrence.Parse(*u.Recurrence.String); err != nil {
+			return err
+		}
+	}
+
+	return validateSchedule(u.StartAt.Time, u.DueAt.Time)
+}
+
+func validateSchedule(startAt, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && dueAt.Before(*startAt) {
+		return errors.New("due_at must not be before start_at")
 	}
 
 	return nil
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// ScopeRead allows only safe (GET) requests.
+	ScopeRead = "read"
+	// ScopeWrite allows every request ScopeRead does and changes.
+	ScopeWrite = "write"
+)
+
+type PersonalAccessToken struct {
+	Id         int        `json:"id" db:"id"`
+	UserId     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Scope      string     `json:"scope" db:"scope"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// Allows reports whether the token may be used for a request with the given HTTP method.
+func (t PersonalAccessToken) Allows(method string) bool {
+	switch t.Scope {
+	case ScopeWrite:
+		return true
+	case ScopeRead:
+		return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	default:
+		return false
+	}
+}
+
+type CreateTokenInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Scope     string     `json:"scope" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (i CreateTokenInput) Validate() error {
+	if i.Scope != ScopeRead && i.Scope != ScopeWrite {
+		return errors.New("scope must be either read or write")
+	}
+
+	if i.ExpiresAt != nil && i.ExpiresAt.Before(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+// CreatedToken is returned once on creation, the plain token can not be
+// recovered afterward.
+type CreatedToken struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
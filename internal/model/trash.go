@@ -0,0 +1,30 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	TrashTypeList = "list"
+	TrashTypeItem = "item"
+)
+
+// TrashEntry is a list or an item in the trash. Items deleted with their list
+// are not listed on their own, they come back with the list.
+type TrashEntry struct {
+	Type      string    `json:"type" db:"type"`
+	Id        int       `json:"id" db:"id"`
+	ListId    int       `json:"list_id" db:"list_id"`
+	Title     string    `json:"title" db:"title"`
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at"`
+}
+
+func ValidateTrashType(entryType string) error {
+	switch entryType {
+	case TrashTypeList, TrashTypeItem:
+		return nil
+	default:
+		return fmt.Errorf("type must be %s or %s", TrashTypeList, TrashTypeItem)
+	}
+}
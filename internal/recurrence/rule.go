@@ -0,0 +1,340 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+const (
+	maxInterval = 1000
+	maxCount    = 10000
+)
+
+// Next jumps from one selected period to the next, so its cost does not grow
+// with INTERVAL. It looks at least minPeriods periods ahead and on up to
+// searchYears, a full Gregorian cycle, so a rule that can match does.
+const (
+	minPeriods  = 8
+	searchYears = 400
+)
+
+// untilLayouts are the RFC 5545 DATE-TIME (UTC or floating) and DATE forms.
+var untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is the subset of an RFC 5545 RRULE the app supports: FREQ, INTERVAL,
+// BYDAY without ordinals, BYMONTHDAY, COUNT and UNTIL. Weeks start on Monday.
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// Parse reads a rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR". An optional
+// "RRULE:" prefix is accepted.
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return rule, errors.New("recurrence rule is empty")
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return rule, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		name = strings.ToUpper(name)
+		if seen[name] {
+			return rule, fmt.Errorf("recurrence rule part %s is repeated", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq, err = parseFreq(value)
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(name, value, maxInterval)
+		case "COUNT":
+			rule.Count, err = parsePositive(name, value, maxCount)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = fmt.Errorf("recurrence rule part %s is not supported", name)
+		}
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, errors.New("recurrence rule must have FREQ")
+	}
+
+	if rule.Count > 0 && rule.Until != nil {
+		return rule, errors.New("recurrence rule must not have both COUNT and UNTIL")
+	}
+
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return rule, errors.New("BYMONTHDAY can not be used with FREQ=WEEKLY")
+	}
+
+	return rule, nil
+}
+
+// String formats the rule in canonical form, so equal rules compare equal.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after prev, the occurrence with the given
+// 1-based index in the series. Periods are counted and the time of day is kept
+// from prev. It returns false when the series has ended.
+func (r Rule) Next(prev time.Time, index int) (time.Time, bool) {
+	if r.Count > 0 && index >= r.Count {
+		return time.Time{}, false
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	horizon := prev.AddDate(searchYears, 0, 0)
+	first := r.periodStart(prev)
+
+	for n := 0; ; n++ {
+		start, days := r.period(first, n*interval)
+		if n >= minPeriods && start.After(horizon) {
+			return time.Time{}, false
+		}
+
+		for offset := 0; offset < days; offset++ {
+			day := time.Date(start.Year(), start.Month(), start.Day()+offset,
+				prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+			if !day.After(prev) || !r.matchesDay(prev, day) {
+				continue
+			}
+
+			if r.Until != nil && day.After(*r.Until) {
+				return time.Time{}, false
+			}
+
+			return day, true
+		}
+	}
+}
+
+// periodStart returns the first day of the period (day, week, month or year)
+// prev is in, at midnight.
+func (r Rule) periodStart(prev time.Time) time.Time {
+	year, month, day := prev.Date()
+
+	switch r.Freq {
+	case Daily:
+		return time.Date(year, month, day, 0, 0, 0, 0, prev.Location())
+	case Weekly:
+		offset := (int(prev.Weekday()) + 6) % 7 // Monday is 0
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, prev.Location())
+	case Monthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, prev.Location())
+	default:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, prev.Location())
+	}
+}
+
+// period returns the first day and the length in days of the period that is
+// the given number of periods after first.
+func (r Rule) period(first time.Time, periods int) (time.Time, int) {
+	year, month, day := first.Date()
+	loc := first.Location()
+
+	switch r.Freq {
+	case Daily:
+		return time.Date(year, month, day+periods, 0, 0, 0, 0, loc), 1
+	case Weekly:
+		return time.Date(year, month, day+7*periods, 0, 0, 0, 0, loc), 7
+	case Monthly:
+		start := time.Date(year, month+time.Month(periods), 1, 0, 0, 0, 0, loc)
+		return start, daysIn(start.Year(), start.Month())
+	default:
+		start := time.Date(year+periods, time.January, 1, 0, 0, 0, 0, loc)
+		return start, time.Date(start.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	}
+}
+
+// matchesDay applies BYDAY and BYMONTHDAY. Without them a weekly rule repeats
+// on the weekday of prev, a monthly one on its day of month and a yearly one
+// on its date.
+func (r Rule) matchesDay(prev, day time.Time) bool {
+	if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, day.Weekday()) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, day) {
+		return false
+	}
+
+	if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+		return true
+	}
+
+	switch r.Freq {
+	case Weekly:
+		return day.Weekday() == prev.Weekday()
+	case Monthly:
+		return day.Day() == prev.Day()
+	case Yearly:
+		return day.Month() == prev.Month() && day.Day() == prev.Day()
+	default:
+		return true
+	}
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay supports negative days counting from the end of the month,
+// -1 being the last day.
+func matchesMonthDay(days []int, day time.Time) bool {
+	daysInMonth := daysIn(day.Year(), day.Month())
+	for _, d := range days {
+		if d == day.Day() || d < 0 && daysInMonth+d+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func parseFreq(value string) (string, error) {
+	freq := strings.ToUpper(value)
+	switch freq {
+	case Daily, Weekly, Monthly, Yearly:
+		return freq, nil
+	default:
+		return "", fmt.Errorf("FREQ %s is not supported", value)
+	}
+}
+
+func parsePositive(name, value string, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > max {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, max)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (*time.Time, error) {
+	for _, layout := range untilLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// a date includes the whole day
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("UNTIL %s is not a valid date or date-time", value)
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		day, ok := weekdays[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("BYDAY %s is not supported, only weekdays like MO or FR are", name)
+		}
+		if !containsWeekday(days, day) {
+			days = append(days, day)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return (days[i]+6)%7 < (days[j]+6)%7
+	})
+
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, s := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(s)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("BYMONTHDAY %s must be between 1 and 31 or -31 and -1", s)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
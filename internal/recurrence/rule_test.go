@@ -0,0 +1,364 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"daily", "FREQ=DAILY", "FREQ=DAILY"},
+		{"prefix and case", "RRULE:freq=weekly;byday=fr,mo", "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{"interval one is dropped", "FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"interval", "INTERVAL=2;FREQ=WEEKLY", "FREQ=WEEKLY;INTERVAL=2"},
+		{"byday is sorted from monday", "FREQ=WEEKLY;BYDAY=SU,WE,MO,WE", "FREQ=WEEKLY;BYDAY=MO,WE,SU"},
+		{"bymonthday", "FREQ=MONTHLY;BYMONTHDAY=1,15,-1", "FREQ=MONTHLY;BYMONTHDAY=1,15,-1"},
+		{"count", "FREQ=YEARLY;COUNT=3", "FREQ=YEARLY;COUNT=3"},
+		{"until date", "FREQ=DAILY;UNTIL=20241231", "FREQ=DAILY;UNTIL=20241231T235959Z"},
+		{"until utc", "FREQ=DAILY;UNTIL=20241231T100000Z", "FREQ=DAILY;UNTIL=20241231T100000Z"},
+		{"until floating", "FREQ=DAILY;UNTIL=20241231T100000", "FREQ=DAILY;UNTIL=20241231T100000Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+
+			if got := rule.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.input, got, tt.want)
+			}
+
+			again, err := Parse(rule.String())
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", rule.String(), err)
+			}
+			if again.String() != rule.String() {
+				t.Errorf("round trip of %q gave %q", rule.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"only prefix", "RRULE:"},
+		{"no freq", "INTERVAL=2"},
+		{"unknown freq", "FREQ=HOURLY"},
+		{"missing value", "FREQ="},
+		{"missing equals", "FREQ"},
+		{"repeated part", "FREQ=DAILY;FREQ=WEEKLY"},
+		{"unsupported part", "FREQ=DAILY;BYSETPOS=1"},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0"},
+		{"interval too large", "FREQ=DAILY;INTERVAL=1001"},
+		{"interval not a number", "FREQ=DAILY;INTERVAL=two"},
+		{"zero count", "FREQ=DAILY;COUNT=0"},
+		{"count too large", "FREQ=DAILY;COUNT=10001"},
+		{"count and until", "FREQ=DAILY;COUNT=2;UNTIL=20241231"},
+		{"bad until", "FREQ=DAILY;UNTIL=2024-12-31"},
+		{"byday ordinal", "FREQ=MONTHLY;BYDAY=1MO"},
+		{"bad byday", "FREQ=WEEKLY;BYDAY=XX"},
+		{"zero bymonthday", "FREQ=MONTHLY;BYMONTHDAY=0"},
+		{"bymonthday too large", "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{"bymonthday too small", "FREQ=MONTHLY;BYMONTHDAY=-32"},
+		{"weekly bymonthday", "FREQ=WEEKLY;BYMONTHDAY=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rule, err := Parse(tt.input); err == nil {
+				t.Errorf("Parse(%q) = %q, want an error", tt.input, rule)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		prev  string
+		index int
+		want  []string // the following occurrences, empty when the series ends
+	}{
+		{
+			name: "daily",
+			rule: "FREQ=DAILY",
+			prev: "2024-04-29T09:00:00Z",
+			want: []string{"2024-04-30T09:00:00Z", "2024-05-01T09:00:00Z"},
+		},
+		{
+			name: "daily interval",
+			rule: "FREQ=DAILY;INTERVAL=3",
+			prev: "2024-04-29T09:00:00Z",
+			want: []string{"2024-05-02T09:00:00Z", "2024-05-05T09:00:00Z"},
+		},
+		{
+			name: "daily byday",
+			rule: "FREQ=DAILY;BYDAY=MO,WE,FR",
+			prev: "2024-05-03T09:00:00Z",
+			want: []string{"2024-05-06T09:00:00Z", "2024-05-08T09:00:00Z", "2024-05-10T09:00:00Z"},
+		},
+		{
+			name: "daily bymonthday",
+			rule: "FREQ=DAILY;BYMONTHDAY=31",
+			prev: "2024-04-01T09:00:00Z",
+			want: []string{"2024-05-31T09:00:00Z", "2024-07-31T09:00:00Z"},
+		},
+		{
+			name: "weekly keeps the weekday",
+			rule: "FREQ=WEEKLY",
+			prev: "2024-05-01T09:00:00Z",
+			want: []string{"2024-05-08T09:00:00Z", "2024-05-15T09:00:00Z"},
+		},
+		{
+			name: "weekly byday in the same week",
+			rule: "FREQ=WEEKLY;BYDAY=MO,FR",
+			prev: "2024-04-29T09:00:00Z",
+			want: []string{"2024-05-03T09:00:00Z", "2024-05-06T09:00:00Z"},
+		},
+		{
+			name: "weekly interval skips weeks",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			prev: "2024-04-29T09:00:00Z",
+			want: []string{"2024-05-03T09:00:00Z", "2024-05-13T09:00:00Z", "2024-05-17T09:00:00Z"},
+		},
+		{
+			name: "weekly interval from sunday",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			prev: "2024-05-05T09:00:00Z",
+			want: []string{"2024-05-13T09:00:00Z", "2024-05-27T09:00:00Z"},
+		},
+		{
+			name: "monthly keeps the day",
+			rule: "FREQ=MONTHLY",
+			prev: "2024-01-15T09:00:00Z",
+			want: []string{"2024-02-15T09:00:00Z", "2024-03-15T09:00:00Z"},
+		},
+		{
+			name: "monthly skips months without the day",
+			rule: "FREQ=MONTHLY",
+			prev: "2024-01-31T09:00:00Z",
+			want: []string{"2024-03-31T09:00:00Z", "2024-05-31T09:00:00Z"},
+		},
+		{
+			name: "monthly interval",
+			rule: "FREQ=MONTHLY;INTERVAL=3",
+			prev: "2024-11-10T09:00:00Z",
+			want: []string{"2025-02-10T09:00:00Z", "2025-05-10T09:00:00Z"},
+		},
+		{
+			name: "monthly bymonthday",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			prev: "2024-04-15T09:00:00Z",
+			want: []string{"2024-05-01T09:00:00Z", "2024-05-15T09:00:00Z"},
+		},
+		{
+			name: "monthly last day",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			prev: "2024-01-31T09:00:00Z",
+			want: []string{"2024-02-29T09:00:00Z", "2024-03-31T09:00:00Z", "2024-04-30T09:00:00Z"},
+		},
+		{
+			name: "monthly second to last day",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-2",
+			prev: "2023-01-30T09:00:00Z",
+			want: []string{"2023-02-27T09:00:00Z", "2023-03-30T09:00:00Z"},
+		},
+		{
+			name: "monthly byday and bymonthday",
+			rule: "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			prev: "2024-01-01T09:00:00Z",
+			want: []string{"2024-09-13T09:00:00Z", "2024-12-13T09:00:00Z"},
+		},
+		{
+			name: "yearly keeps the date",
+			rule: "FREQ=YEARLY",
+			prev: "2024-04-15T09:00:00Z",
+			want: []string{"2025-04-15T09:00:00Z", "2026-04-15T09:00:00Z"},
+		},
+		{
+			name: "yearly from february 29",
+			rule: "FREQ=YEARLY",
+			prev: "2024-02-29T09:00:00Z",
+			want: []string{"2028-02-29T09:00:00Z", "2032-02-29T09:00:00Z"},
+		},
+		{
+			name: "yearly february 29 over a century",
+			rule: "FREQ=YEARLY;INTERVAL=25",
+			prev: "2000-02-29T09:00:00Z",
+			want: []string{"2400-02-29T09:00:00Z"},
+		},
+		{
+			name: "yearly byday",
+			rule: "FREQ=YEARLY;BYDAY=MO",
+			prev: "2024-04-29T09:00:00Z",
+			want: []string{"2024-05-06T09:00:00Z", "2024-05-13T09:00:00Z"},
+		},
+		{
+			name: "yearly bymonthday",
+			rule: "FREQ=YEARLY;BYMONTHDAY=1",
+			prev: "2024-04-15T09:00:00Z",
+			want: []string{"2024-05-01T09:00:00Z", "2024-06-01T09:00:00Z"},
+		},
+		{
+			name: "yearly bymonthday 31",
+			rule: "FREQ=YEARLY;BYMONTHDAY=31",
+			prev: "2024-04-15T09:00:00Z",
+			want: []string{"2024-05-31T09:00:00Z", "2024-07-31T09:00:00Z"},
+		},
+		{
+			name: "yearly interval bymonthday",
+			rule: "FREQ=YEARLY;INTERVAL=2;BYMONTHDAY=-1",
+			prev: "2024-12-31T09:00:00Z",
+			want: []string{"2026-01-31T09:00:00Z", "2026-02-28T09:00:00Z"},
+		},
+		{
+			name: "yearly large interval",
+			rule: "FREQ=YEARLY;INTERVAL=1000",
+			prev: "2024-04-15T09:00:00Z",
+			want: []string{"3024-04-15T09:00:00Z"},
+		},
+		{
+			name:  "count allows the last occurrence",
+			rule:  "FREQ=DAILY;COUNT=3",
+			prev:  "2024-04-29T09:00:00Z",
+			index: 1,
+			want:  []string{"2024-04-30T09:00:00Z", "2024-05-01T09:00:00Z"},
+		},
+		{
+			name:  "count ends the series",
+			rule:  "FREQ=DAILY;COUNT=3",
+			prev:  "2024-04-29T09:00:00Z",
+			index: 3,
+		},
+		{
+			name: "until date includes the day",
+			rule: "FREQ=DAILY;UNTIL=20240501",
+			prev: "2024-04-29T23:00:00Z",
+			want: []string{"2024-04-30T23:00:00Z", "2024-05-01T23:00:00Z"},
+		},
+		{
+			name: "until date-time",
+			rule: "FREQ=DAILY;UNTIL=20240501T080000Z",
+			prev: "2024-04-29T09:00:00Z",
+			want: []string{"2024-04-30T09:00:00Z"},
+		},
+		{
+			name: "never matches",
+			rule: "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31",
+			prev: "2024-04-15T09:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.rule, err)
+			}
+
+			prev := mustTime(t, tt.prev)
+			index := tt.index
+			if index == 0 {
+				index = 1
+			}
+
+			for _, want := range tt.want {
+				next, ok := rule.Next(prev, index)
+				if !ok {
+					t.Fatalf("Next(%s, %d) ended, want %s", prev.Format(time.RFC3339), index, want)
+				}
+				if !next.Equal(mustTime(t, want)) {
+					t.Fatalf("Next(%s, %d) = %s, want %s", prev.Format(time.RFC3339), index, next.Format(time.RFC3339), want)
+				}
+				prev = next
+				index++
+			}
+
+			if len(tt.want) == 0 || rule.Count > 0 || rule.Until != nil {
+				if next, ok := rule.Next(prev, index); ok {
+					t.Errorf("Next(%s, %d) = %s, want the series to end", prev.Format(time.RFC3339), index, next.Format(time.RFC3339))
+				}
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocalTimeOverDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data is not available: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		rule string
+		prev time.Time
+		want time.Time
+	}{
+		{
+			name: "daily into summer time",
+			rule: "FREQ=DAILY",
+			prev: time.Date(2024, time.March, 30, 9, 0, 0, 0, loc),
+			want: time.Date(2024, time.March, 31, 9, 0, 0, 0, loc),
+		},
+		{
+			name: "weekly out of summer time",
+			rule: "FREQ=WEEKLY",
+			prev: time.Date(2024, time.October, 21, 9, 0, 0, 0, loc),
+			want: time.Date(2024, time.October, 28, 9, 0, 0, 0, loc),
+		},
+		{
+			name: "monthly over both changes",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			prev: time.Date(2024, time.March, 15, 23, 30, 0, 0, loc),
+			want: time.Date(2024, time.March, 31, 23, 30, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.rule, err)
+			}
+
+			next, ok := rule.Next(tt.prev, 1)
+			if !ok || !next.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, %v, want %s", tt.prev, next, ok, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkNextLargeInterval(b *testing.B) {
+	rule, err := Parse("FREQ=YEARLY;INTERVAL=1000")
+	if err != nil {
+		b.Fatal(err)
+	}
+	prev := time.Date(2024, time.April, 15, 9, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		rule.Next(prev, 1)
+	}
+}
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("time.Parse(%q) error: %v", s, err)
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+var activitySorts = map[string]sortColumn{
+	"created_at": {asc: "a.created_at", sqlType: "timestamptz"},
+}
+
+type ActivityPostgres struct {
+	db *sqlx.DB
+}
+
+func NewActivityPostgres(db *sqlx.DB) *ActivityPostgres {
+	return &ActivityPostgres{db: db}
+}
+
+// GetByList returns a page of the history of a list and the items in it, by
+// default the latest first, and the cursor of the next page.
+func (r *ActivityPostgres) GetByList(ctx context.Context, userId, listId int, page model.PageQuery) ([]model.Activity, string, error) {
+	if _, err := getListRole(ctx, r.db, userId, listId); err != nil {
+		return nil, "", err
+	}
+
+	return r.selectActivities(ctx, "a.list_id = $1", listId, page)
+}
+
+// GetByItem returns a page of the history of an item, by default the latest
+// first, and the cursor of the next page.
+func (r *ActivityPostgres) GetByItem(ctx context.Context, userId, itemId int, page model.PageQuery) ([]model.Activity, string, error) {
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return nil, "", err
+	}
+
+	return r.selectActivities(ctx, fmt.Sprintf("a.entity_type = '%s' AND a.entity_id = $1", model.EntityItem), itemId, page)
+}
+
+func (r *ActivityPostgres) selectActivities(ctx context.Context, condition string, arg int, page model.PageQuery) ([]model.Activity, string, error) {
+	keys, err := newKeyset(activitySorts, page, "-created_at", "a.id")
+	if err != nil {
+		return nil, "", err
+	}
+
+	conditions := []string{condition}
+	args := []interface{}{arg}
+	argId := 2
+
+	if keyCondition, keyArgs := keys.condition(argId); keyCondition != "" {
+		conditions = append(conditions, keyCondition)
+		args = append(args, keyArgs...)
+		argId += len(keyArgs)
+	}
+
+	var rows []struct {
+		model.Activity
+		SortKey string `db:"sort_key"`
+	}
+	query := fmt.Sprintf(`SELECT a.id, a.actor_id, u.username AS actor_username, a.entity_type, a.entity_id, a.list_id, a.action, a.changes, a.created_at, %s
+									FROM %s a LEFT JOIN %s u ON u.id = a.actor_id WHERE %s ORDER BY %s LIMIT $%d`,
+		keys.column(), activityLogTable, usersTable, strings.Join(conditions, " AND "), keys.orderBy(), argId)
+	args = append(args, keys.fetch())
+
+	if err = r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(rows) > 0 {
+		last := min(len(rows), keys.limit) - 1
+		next = keys.next(len(rows), rows[last].SortKey, rows[last].Id)
+		rows = rows[:last+1]
+	}
+
+	activities := make([]model.Activity, len(rows))
+	for i := range rows {
+		activities[i] = rows[i].Activity
+	}
+
+	return activities, next, nil
+}
+
+// logActivity records a change in the transaction that makes it. Empty
+// changes are stored as null.
+func logActivity(ctx context.Context, tx *sqlx.Tx, actorId int, entityType string, entityId, listId int, action string, changes model.Changes) error {
+	var changesJSON interface{}
+	if len(changes) > 0 {
+		data, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		changesJSON = data
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (actor_id, entity_type, entity_id, list_id, action, changes) VALUES ($1, $2, $3, $4, $5, $6)", activityLogTable)
+	_, err := tx.ExecContext(ctx, query, actorId, entityType, entityId, listId, action, changesJSON)
+	return err
+}
+
+// itemListId returns the list of an item.
+func itemListId(ctx context.Context, q sqlx.QueryerContext, itemId int) (int, error) {
+	var listId int
+	query := fmt.Sprintf("SELECT list_id FROM %s WHERE item_id = $1", listsItemsTable)
+	err := sqlx.GetContext(ctx, q, &listId, query, itemId)
+	return listId, err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+const attachmentColumns = "id, item_id, uploader_id, filename, content_type, size, blob_key, created_at"
+
+type AttachmentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentPostgres(db *sqlx.DB) *AttachmentPostgres {
+	return &AttachmentPostgres{db: db}
+}
+
+// CanWrite returns the error Create would for the user and the item, so an
+// upload can be refused before its contents are stored.
+func (r *AttachmentPostgres) CanWrite(ctx context.Context, userId, itemId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = requireActiveItemWrite(ctx, tx, userId, itemId)
+	_ = tx.Rollback()
+	return err
+}
+
+// Create stores the metadata of an uploaded blob. The user must be allowed to
+// change the item and its list must not be archived.
+func (r *AttachmentPostgres) Create(ctx context.Context, userId, itemId int, attachment model.Attachment) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (item_id, uploader_id, filename, content_type, size, blob_key) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		attachmentsTable)
+	err = tx.QueryRowContext(ctx, query, itemId, userId, attachment.Filename, attachment.ContentType, attachment.Size, attachment.BlobKey).Scan(&id)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *AttachmentPostgres) GetAll(ctx context.Context, userId, itemId int) ([]model.Attachment, error) {
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return nil, err
+	}
+
+	var attachments []model.Attachment
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE item_id = $1 ORDER BY created_at, id", attachmentColumns, attachmentsTable)
+	err := r.db.SelectContext(ctx, &attachments, query, itemId)
+	return attachments, err
+}
+
+func (r *AttachmentPostgres) GetById(ctx context.Context, userId, itemId, attachmentId int) (model.Attachment, error) {
+	var attachment model.Attachment
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return attachment, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE item_id = $1 AND id = $2", attachmentColumns, attachmentsTable)
+	err := r.db.GetContext(ctx, &attachment, query, itemId, attachmentId)
+	return attachment, err
+}
+
+// Delete removes the metadata of an attachment and returns the key of its
+// blob, which the caller deletes.
+func (r *AttachmentPostgres) Delete(ctx context.Context, userId, itemId, attachmentId int) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	var blobKey string
+	query := fmt.Sprintf("DELETE FROM %s WHERE item_id = $1 AND id = $2 RETURNING blob_key", attachmentsTable)
+	if err = tx.GetContext(ctx, &blobKey, query, itemId, attachmentId); err != nil {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	return blobKey, tx.Commit()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"TodoApp/internal/model"
+	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 )
@@ -14,10 +15,10 @@ func NewAuthPostgres(db *sqlx.DB) *AuthPostgres {
 	return &AuthPostgres{db: db}
 }
 
-func (r *AuthPostgres) CreateUser(user model.User) (int, error) {
+func (r *AuthPostgres) CreateUser(ctx context.Context, user model.User) (int, error) {
 	var id int
 	query := fmt.Sprintf("INSERT INTO %s (name, username, password_hash) VALUES($1, $2, $3) RETURNING id", usersTable)
-	row := r.db.QueryRow(query, user.Name, user.Username, user.Password)
+	row := r.db.QueryRowContext(ctx, query, user.Name, user.Username, user.Password)
 
 	if err := row.Scan(&id); err != nil {
 		return 0, err
@@ -26,9 +27,15 @@ func (r *AuthPostgres) CreateUser(user model.User) (int, error) {
 	return id, nil
 }
 
-func (r *AuthPostgres) GetUser(username, password string) (model.User, error) {
+func (r *AuthPostgres) GetUser(ctx context.Context, username string) (model.User, error) {
 	var user model.User
-	query := fmt.Sprintf("SELECT * FROM %s WHERE username = $1 AND password_hash = $2", usersTable)
-	err := r.db.Get(&user, query, username, password)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE username = $1", usersTable)
+	err := r.db.GetContext(ctx, &user, query, username)
 	return user, err
 }
+
+func (r *AuthPostgres) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash = $1 WHERE id = $2", usersTable)
+	_, err := r.db.ExecContext(ctx, query, passwordHash, userId)
+	return err
+}
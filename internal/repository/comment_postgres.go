@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// mentionedMembers keeps the usernames in the array placeholder that belong
+// to members of the list of the item in the item placeholder.
+func mentionedMembers(itemArg, usernamesArg string) string {
+	return fmt.Sprintf(`ARRAY(SELECT u.username FROM %s u INNER JOIN %s ul ON ul.user_id = u.id INNER JOIN %s li ON li.list_id = ul.list_id
+									WHERE li.item_id = %s AND u.username = ANY(%s) ORDER BY u.username)`,
+		usersTable, usersListsTable, listsItemsTable, itemArg, usernamesArg)
+}
+
+type CommentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewCommentPostgres(db *sqlx.DB) *CommentPostgres {
+	return &CommentPostgres{db: db}
+}
+
+// Create adds a comment by the user. Every member of the list may comment,
+// viewers too.
+func (r *CommentPostgres) Create(ctx context.Context, userId, itemId int, comment model.Comment, mentions []string) (int, error) {
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (item_id, author_id, body, mentions) VALUES ($1, $2, $3, %s) RETURNING id",
+		commentsTable, mentionedMembers("$1", "$4"))
+	if err := r.db.QueryRowContext(ctx, query, itemId, userId, comment.Body, pq.Array(mentions)).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *CommentPostgres) GetAll(ctx context.Context, userId, itemId int) ([]model.Comment, error) {
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		model.Comment
+		Mentions pq.StringArray `db:"mentions"`
+	}
+	query := fmt.Sprintf(`SELECT c.id, c.item_id, c.author_id, u.username AS author_username, c.body, c.mentions, c.edited, c.created_at, c.updated_at
+									FROM %s c LEFT JOIN %s u ON u.id = c.author_id WHERE c.item_id = $1 ORDER BY c.created_at, c.id`,
+		commentsTable, usersTable)
+	if err := r.db.SelectContext(ctx, &rows, query, itemId); err != nil {
+		return nil, err
+	}
+
+	comments := make([]model.Comment, len(rows))
+	for i := range rows {
+		comments[i] = rows[i].Comment
+		comments[i].Mentions = rows[i].Mentions
+	}
+
+	return comments, nil
+}
+
+// Update changes the body of a comment of the user. A comment is marked as
+// edited once its body differs from the original.
+func (r *CommentPostgres) Update(ctx context.Context, userId, itemId, commentId int, input model.UpdateCommentInput, mentions []string) error {
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET body = $1, mentions = %s, edited = edited OR body <> $1, updated_at = NOW()
+									WHERE item_id = $2 AND id = $3 AND author_id = $4`,
+		commentsTable, mentionedMembers("$2", "$5"))
+	res, err := r.db.ExecContext(ctx, query, input.Body, itemId, commentId, userId, pq.Array(mentions))
+	if err != nil {
+		return err
+	}
+
+	return r.checkCommentAffected(ctx, res, itemId, commentId, model.ErrNotCommentAuthor)
+}
+
+// Delete removes a comment. Authors may delete their own comments, owners of
+// the list any comment.
+func (r *CommentPostgres) Delete(ctx context.Context, userId, itemId, commentId int) error {
+	role, err := getItemRole(ctx, r.db, userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE item_id = $1 AND id = $2 AND (author_id = $3 OR $4)", commentsTable)
+	res, err := r.db.ExecContext(ctx, query, itemId, commentId, userId, role == model.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return r.checkCommentAffected(ctx, res, itemId, commentId, model.ErrForbidden)
+}
+
+// checkCommentAffected explains a write that matched no comment: forbidden
+// if the comment exists, sql.ErrNoRows otherwise.
+func (r *CommentPostgres) checkCommentAffected(ctx context.Context, res sql.Result, itemId, commentId int, forbidden error) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var id int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE item_id = $1 AND id = $2", commentsTable)
+	if err = r.db.GetContext(ctx, &id, query, itemId, commentId); err != nil {
+		return err
+	}
+
+	return forbidden
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+// filterColumns maps the fields of model.FilterFields to SQL over the ti and
+// li aliases. label_id is compiled to a lookup of the user's own labels in
+// items_labels instead.
+var filterColumns = map[string]string{
+	"title":        "ti.title",
+	"description":  "ti.description",
+	"priority":     "ti.priority",
+	"done":         "ti.done",
+	"list_id":      "li.list_id",
+	"start_at":     "ti.start_at",
+	"due_at":       "ti.due_at",
+	"completed_at": "ti.completed_at",
+	"created_at":   "ti.created_at",
+	"updated_at":   "ti.updated_at",
+}
+
+var filterComparisons = map[string]string{
+	model.OpEq:  "=",
+	model.OpNe:  "IS DISTINCT FROM",
+	model.OpLt:  "<",
+	model.OpLte: "<=",
+	model.OpGt:  ">",
+	model.OpGte: ">=",
+}
+
+// filterCompiler turns a filter expression into a SQL condition. Values are
+// only ever passed as arguments, placeholders start at argId.
+type filterCompiler struct {
+	args   []interface{}
+	argId  int
+	userId int
+	now    time.Time
+}
+
+// compileFilter returns the condition for expr and its arguments. Labels only
+// match if they belong to the user, relative times in expr are resolved
+// against now.
+func compileFilter(expr model.FilterExpr, userId, argId int, now time.Time) (string, []interface{}, error) {
+	c := &filterCompiler{argId: argId, userId: userId, now: now}
+	condition, err := c.compile(expr)
+	return condition, c.args, err
+}
+
+func (c *filterCompiler) compile(e model.FilterExpr) (string, error) {
+	switch {
+	case len(e.And) > 0:
+		return c.join(e.And, " AND ")
+	case len(e.Or) > 0:
+		return c.join(e.Or, " OR ")
+	case e.Not != nil:
+		condition, err := c.compile(*e.Not)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + condition, nil
+	default:
+		return c.compare(e)
+	}
+}
+
+func (c *filterCompiler) join(exprs []model.FilterExpr, operator string) (string, error) {
+	conditions := make([]string, len(exprs))
+	for i, expr := range exprs {
+		condition, err := c.compile(expr)
+		if err != nil {
+			return "", err
+		}
+		conditions[i] = condition
+	}
+	return "(" + strings.Join(conditions, operator) + ")", nil
+}
+
+func (c *filterCompiler) compare(e model.FilterExpr) (string, error) {
+	values, err := e.Values(c.now)
+	if err != nil {
+		return "", err
+	}
+
+	if e.Field == "label_id" {
+		labelIds := c.arg(intArray(values))
+		labels := fmt.Sprintf("EXISTS (SELECT 1 FROM %s il INNER JOIN %s l ON l.id = il.label_id AND l.user_id = %s WHERE il.item_id = ti.id AND il.label_id = ANY(%s))",
+			itemsLabelsTable, labelsTable, c.arg(c.userId), labelIds)
+		if e.Op == model.OpNe {
+			return "NOT " + labels, nil
+		}
+		return labels, nil
+	}
+
+	column, ok := filterColumns[e.Field]
+	if !ok {
+		return "", fmt.Errorf("filter field %s has no column", e.Field)
+	}
+
+	switch e.Op {
+	case model.OpIsNull:
+		if values[0].(bool) {
+			return fmt.Sprintf("(%s IS NULL)", column), nil
+		}
+		return fmt.Sprintf("(%s IS NOT NULL)", column), nil
+	case model.OpContains:
+		return fmt.Sprintf("(%s ILIKE %s)", column, c.arg(containsPattern(values[0].(string)))), nil
+	case model.OpIn:
+		if model.FilterFields[e.Field] == model.FieldInt {
+			return fmt.Sprintf("(%s = ANY(%s))", column, c.arg(intArray(values))), nil
+		}
+		return fmt.Sprintf("(%s = ANY(%s))", column, c.arg(stringArray(values))), nil
+	default:
+		return fmt.Sprintf("(%s %s %s)", column, filterComparisons[e.Op], c.arg(values[0])), nil
+	}
+}
+
+// arg adds an argument and returns its placeholder.
+func (c *filterCompiler) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	c.argId++
+	return fmt.Sprintf("$%d", c.argId-1)
+}
+
+func intArray(values []interface{}) interface{} {
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i] = int64(v.(int))
+	}
+	return pq.Array(ints)
+}
+
+func stringArray(values []interface{}) interface{} {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.(string)
+	}
+	return pq.Array(strs)
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const testUserId = 42
+
+func TestCompileFilter(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		input     string
+		condition string
+		args      []interface{}
+	}{
+		{
+			name:      "text eq",
+			input:     `{"field": "title", "op": "eq", "value": "report"}`,
+			condition: "(ti.title = $1)",
+			args:      []interface{}{"report"},
+		},
+		{
+			name:      "text ne",
+			input:     `{"field": "description", "op": "ne", "value": "report"}`,
+			condition: "(ti.description IS DISTINCT FROM $1)",
+			args:      []interface{}{"report"},
+		},
+		{
+			name:      "contains escapes patterns",
+			input:     `{"field": "title", "op": "contains", "value": "50%_off"}`,
+			condition: "(ti.title ILIKE $1)",
+			args:      []interface{}{`%50\%\_off%`},
+		},
+		{
+			name:      "text in",
+			input:     `{"field": "title", "op": "in", "value": ["a", "b"]}`,
+			condition: "(ti.title = ANY($1))",
+			args:      []interface{}{pq.Array([]string{"a", "b"})},
+		},
+		{
+			name:      "priority eq",
+			input:     `{"field": "priority", "op": "eq", "value": "high"}`,
+			condition: "(ti.priority = $1)",
+			args:      []interface{}{"high"},
+		},
+		{
+			name:      "priority in",
+			input:     `{"field": "priority", "op": "in", "value": ["low", "urgent"]}`,
+			condition: "(ti.priority = ANY($1))",
+			args:      []interface{}{pq.Array([]string{"low", "urgent"})},
+		},
+		{
+			name:      "bool eq",
+			input:     `{"field": "done", "op": "eq", "value": false}`,
+			condition: "(ti.done = $1)",
+			args:      []interface{}{false},
+		},
+		{
+			name:      "int eq",
+			input:     `{"field": "list_id", "op": "eq", "value": 7}`,
+			condition: "(li.list_id = $1)",
+			args:      []interface{}{7},
+		},
+		{
+			name:      "int ne",
+			input:     `{"field": "list_id", "op": "ne", "value": 7}`,
+			condition: "(li.list_id IS DISTINCT FROM $1)",
+			args:      []interface{}{7},
+		},
+		{
+			name:      "int in",
+			input:     `{"field": "list_id", "op": "in", "value": [7, 8]}`,
+			condition: "(li.list_id = ANY($1))",
+			args:      []interface{}{pq.Array([]int64{7, 8})},
+		},
+		{
+			name:      "label eq",
+			input:     `{"field": "label_id", "op": "eq", "value": 3}`,
+			condition: "EXISTS (SELECT 1 FROM items_labels il INNER JOIN labels l ON l.id = il.label_id AND l.user_id = $2 WHERE il.item_id = ti.id AND il.label_id = ANY($1))",
+			args:      []interface{}{pq.Array([]int64{3}), testUserId},
+		},
+		{
+			name:      "label ne",
+			input:     `{"field": "label_id", "op": "ne", "value": 3}`,
+			condition: "NOT EXISTS (SELECT 1 FROM items_labels il INNER JOIN labels l ON l.id = il.label_id AND l.user_id = $2 WHERE il.item_id = ti.id AND il.label_id = ANY($1))",
+			args:      []interface{}{pq.Array([]int64{3}), testUserId},
+		},
+		{
+			name:      "label in",
+			input:     `{"field": "label_id", "op": "in", "value": [3, 4]}`,
+			condition: "EXISTS (SELECT 1 FROM items_labels il INNER JOIN labels l ON l.id = il.label_id AND l.user_id = $2 WHERE il.item_id = ti.id AND il.label_id = ANY($1))",
+			args:      []interface{}{pq.Array([]int64{3, 4}), testUserId},
+		},
+		{
+			name:      "time lt absolute",
+			input:     `{"field": "start_at", "op": "lt", "value": "2024-06-01T08:00:00+02:00"}`,
+			condition: "(ti.start_at < $1)",
+			args:      []interface{}{time.Date(2024, time.June, 1, 6, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:      "time lte relative days",
+			input:     `{"field": "due_at", "op": "lte", "value": "now+7d"}`,
+			condition: "(ti.due_at <= $1)",
+			args:      []interface{}{time.Date(2024, time.May, 17, 12, 30, 0, 0, time.UTC)},
+		},
+		{
+			name:      "time gt relative hours",
+			input:     `{"field": "completed_at", "op": "gt", "value": "now-12h"}`,
+			condition: "(ti.completed_at > $1)",
+			args:      []interface{}{time.Date(2024, time.May, 10, 0, 30, 0, 0, time.UTC)},
+		},
+		{
+			name:      "time gte now",
+			input:     `{"field": "created_at", "op": "gte", "value": "now"}`,
+			condition: "(ti.created_at >= $1)",
+			args:      []interface{}{now},
+		},
+		{
+			name:      "time relative weeks and minutes",
+			input:     `{"or": [{"field": "updated_at", "op": "lt", "value": "now-1w"}, {"field": "updated_at", "op": "gt", "value": "now+45m"}]}`,
+			condition: "((ti.updated_at < $1) OR (ti.updated_at > $2))",
+			args:      []interface{}{time.Date(2024, time.May, 3, 12, 30, 0, 0, time.UTC), time.Date(2024, time.May, 10, 13, 15, 0, 0, time.UTC)},
+		},
+		{
+			name:      "is null",
+			input:     `{"field": "due_at", "op": "is_null", "value": true}`,
+			condition: "(ti.due_at IS NULL)",
+		},
+		{
+			name:      "is not null",
+			input:     `{"field": "due_at", "op": "is_null", "value": false}`,
+			condition: "(ti.due_at IS NOT NULL)",
+		},
+		{
+			name:      "not",
+			input:     `{"not": {"field": "done", "op": "eq", "value": true}}`,
+			condition: "NOT (ti.done = $1)",
+			args:      []interface{}{true},
+		},
+		{
+			name: "nested",
+			input: `{"and": [{"field": "done", "op": "eq", "value": false},
+				{"or": [{"field": "priority", "op": "eq", "value": "high"}, {"not": {"field": "title", "op": "contains", "value": "x"}}]},
+				{"field": "due_at", "op": "is_null", "value": false}]}`,
+			condition: "((ti.done = $1) AND ((ti.priority = $2) OR NOT (ti.title ILIKE $3)) AND (ti.due_at IS NOT NULL))",
+			args:      []interface{}{false, "high", "%x%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, err := compileFilter(mustFilter(t, tt.input), testUserId, 1, now)
+			if err != nil {
+				t.Fatalf("compileFilter() error: %v", err)
+			}
+
+			if condition != tt.condition {
+				t.Errorf("compileFilter() condition = %q, want %q", condition, tt.condition)
+			}
+			assertArgs(t, args, tt.args)
+		})
+	}
+}
+
+func TestCompileFilterCoversFields(t *testing.T) {
+	for field := range model.FilterFields {
+		if _, ok := filterColumns[field]; !ok && field != "label_id" {
+			t.Errorf("filter field %s has no column", field)
+		}
+	}
+}
+
+func TestCompileFilterPlaceholders(t *testing.T) {
+	input := `{"and": [{"field": "title", "op": "eq", "value": "a"}, {"field": "due_at", "op": "is_null", "value": true},
+		{"not": {"field": "list_id", "op": "in", "value": [1, 2]}}, {"field": "label_id", "op": "eq", "value": 3}]}`
+
+	condition, args, err := compileFilter(mustFilter(t, input), testUserId, 5, time.Now())
+	if err != nil {
+		t.Fatalf("compileFilter() error: %v", err)
+	}
+
+	want := "((ti.title = $5) AND (ti.due_at IS NULL) AND NOT (li.list_id = ANY($6)) AND " +
+		"EXISTS (SELECT 1 FROM items_labels il INNER JOIN labels l ON l.id = il.label_id AND l.user_id = $8 WHERE il.item_id = ti.id AND il.label_id = ANY($7)))"
+	if condition != want {
+		t.Errorf("compileFilter() condition = %q, want %q", condition, want)
+	}
+	assertArgs(t, args, []interface{}{"a", pq.Array([]int64{1, 2}), pq.Array([]int64{3}), testUserId})
+}
+
+func TestCompileFilterRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unknown field", `{"field": "owner", "op": "eq", "value": "me"}`},
+		{"unknown op", `{"field": "title", "op": "like", "value": "a"}`},
+		{"op not for kind", `{"field": "due_at", "op": "contains", "value": "now"}`},
+		{"bad value", `{"field": "list_id", "op": "eq", "value": "one"}`},
+		{"nested unknown field", `{"and": [{"field": "done", "op": "eq", "value": true}, {"not": {"field": "owner", "op": "eq", "value": "me"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if condition, _, err := compileFilter(mustFilter(t, tt.input), testUserId, 1, time.Now()); err == nil {
+				t.Errorf("compileFilter() = %q, want an error", condition)
+			}
+		})
+	}
+}
+
+func mustFilter(t *testing.T, input string) model.FilterExpr {
+	t.Helper()
+
+	var expr model.FilterExpr
+	if err := json.Unmarshal([]byte(input), &expr); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %v", input, err)
+	}
+	return expr
+}
+
+func assertArgs(t *testing.T, got, want []interface{}) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("compileFilter() args = %v, want %v", got, want)
+	}
+
+	for i := range got {
+		if gotTime, ok := got[i].(time.Time); ok {
+			if !gotTime.Equal(want[i].(time.Time)) {
+				t.Errorf("arg $%d = %v, want %v", i+1, got[i], want[i])
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("arg $%d = %#v, want %#v", i+1, got[i], want[i])
+		}
+	}
+}
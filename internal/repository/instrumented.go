@@ -0,0 +1,539 @@
+package repository
+
+import (
+	"TodoApp/internal/metrics"
+	"TodoApp/internal/model"
+	"context"
+	"time"
+)
+
+// instrumentation bounds every repository call with the query timeout and
+// records its duration. The xxxInstrumented types below wrap the repositories
+// to apply it to every method, NewRepository applies them.
+type instrumentation struct {
+	timeout time.Duration
+}
+
+// begin starts a call. The returned function must be deferred with the named
+// error result of the call.
+func (i instrumentation) begin(ctx context.Context, repository, method string) (context.Context, func(err *error)) {
+	start := time.Now()
+	cancel := context.CancelFunc(func() {})
+	if i.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+	}
+
+	return ctx, func(err *error) {
+		cancel()
+		metrics.ObserveRepository(repository, method, start, *err)
+	}
+}
+
+type authorizationInstrumented struct {
+	repo Authorization
+	instrumentation
+}
+
+func (r authorizationInstrumented) CreateUser(ctx context.Context, user model.User) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Authorization", "CreateUser")
+	defer done(&err)
+	return r.repo.CreateUser(ctx, user)
+}
+
+func (r authorizationInstrumented) GetUser(ctx context.Context, username string) (_ model.User, err error) {
+	ctx, done := r.begin(ctx, "Authorization", "GetUser")
+	defer done(&err)
+	return r.repo.GetUser(ctx, username)
+}
+
+func (r authorizationInstrumented) UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) (err error) {
+	ctx, done := r.begin(ctx, "Authorization", "UpdatePasswordHash")
+	defer done(&err)
+	return r.repo.UpdatePasswordHash(ctx, userId, passwordHash)
+}
+
+type sessionInstrumented struct {
+	repo Session
+	instrumentation
+}
+
+func (r sessionInstrumented) Create(ctx context.Context, userId int, refreshTokenHash string, expiresAt time.Time) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Session", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, refreshTokenHash, expiresAt)
+}
+
+func (r sessionInstrumented) GetById(ctx context.Context, sessionId int) (_ model.Session, err error) {
+	ctx, done := r.begin(ctx, "Session", "GetById")
+	defer done(&err)
+	return r.repo.GetById(ctx, sessionId)
+}
+
+func (r sessionInstrumented) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (_ model.Session, err error) {
+	ctx, done := r.begin(ctx, "Session", "GetByRefreshTokenHash")
+	defer done(&err)
+	return r.repo.GetByRefreshTokenHash(ctx, refreshTokenHash)
+}
+
+func (r sessionInstrumented) Rotate(ctx context.Context, sessionId int, oldTokenHash, refreshTokenHash string, expiresAt time.Time) (err error) {
+	ctx, done := r.begin(ctx, "Session", "Rotate")
+	defer done(&err)
+	return r.repo.Rotate(ctx, sessionId, oldTokenHash, refreshTokenHash, expiresAt)
+}
+
+func (r sessionInstrumented) Revoke(ctx context.Context, sessionId int) (err error) {
+	ctx, done := r.begin(ctx, "Session", "Revoke")
+	defer done(&err)
+	return r.repo.Revoke(ctx, sessionId)
+}
+
+func (r sessionInstrumented) RevokeRotated(ctx context.Context, refreshTokenHash string) (_ bool, err error) {
+	ctx, done := r.begin(ctx, "Session", "RevokeRotated")
+	defer done(&err)
+	return r.repo.RevokeRotated(ctx, refreshTokenHash)
+}
+
+type tokenInstrumented struct {
+	repo Token
+	instrumentation
+}
+
+func (r tokenInstrumented) Create(ctx context.Context, token model.PersonalAccessToken) (_ model.PersonalAccessToken, err error) {
+	ctx, done := r.begin(ctx, "Token", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, token)
+}
+
+func (r tokenInstrumented) GetAll(ctx context.Context, userId int) (_ []model.PersonalAccessToken, err error) {
+	ctx, done := r.begin(ctx, "Token", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId)
+}
+
+func (r tokenInstrumented) Revoke(ctx context.Context, userId, tokenId int) (err error) {
+	ctx, done := r.begin(ctx, "Token", "Revoke")
+	defer done(&err)
+	return r.repo.Revoke(ctx, userId, tokenId)
+}
+
+func (r tokenInstrumented) Use(ctx context.Context, tokenHash string) (_ model.PersonalAccessToken, err error) {
+	ctx, done := r.begin(ctx, "Token", "Use")
+	defer done(&err)
+	return r.repo.Use(ctx, tokenHash)
+}
+
+type todoListInstrumented struct {
+	repo TodoList
+	instrumentation
+}
+
+func (r todoListInstrumented) Create(ctx context.Context, userId int, list model.TodoList) (_ int, err error) {
+	ctx, done := r.begin(ctx, "TodoList", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, list)
+}
+
+func (r todoListInstrumented) GetAll(ctx context.Context, userId int, filter model.ListFilter, page model.PageQuery) (_ []model.TodoList, _ string, err error) {
+	ctx, done := r.begin(ctx, "TodoList", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId, filter, page)
+}
+
+func (r todoListInstrumented) GetById(ctx context.Context, userId, listId int) (_ model.TodoList, err error) {
+	ctx, done := r.begin(ctx, "TodoList", "GetById")
+	defer done(&err)
+	return r.repo.GetById(ctx, userId, listId)
+}
+
+func (r todoListInstrumented) Reorder(ctx context.Context, userId int, input model.ReorderInput) (err error) {
+	ctx, done := r.begin(ctx, "TodoList", "Reorder")
+	defer done(&err)
+	return r.repo.Reorder(ctx, userId, input)
+}
+
+func (r todoListInstrumented) SetArchived(ctx context.Context, userId, listId int, archived bool) (err error) {
+	ctx, done := r.begin(ctx, "TodoList", "SetArchived")
+	defer done(&err)
+	return r.repo.SetArchived(ctx, userId, listId, archived)
+}
+
+func (r todoListInstrumented) Delete(ctx context.Context, userId, listId int) (err error) {
+	ctx, done := r.begin(ctx, "TodoList", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, listId)
+}
+
+func (r todoListInstrumented) Update(ctx context.Context, userId, listId int, input model.UpdateListInput) (err error) {
+	ctx, done := r.begin(ctx, "TodoList", "Update")
+	defer done(&err)
+	return r.repo.Update(ctx, userId, listId, input)
+}
+
+type listMemberInstrumented struct {
+	repo ListMember
+	instrumentation
+}
+
+func (r listMemberInstrumented) GetAll(ctx context.Context, userId, listId int) (_ []model.ListMember, err error) {
+	ctx, done := r.begin(ctx, "ListMember", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId, listId)
+}
+
+func (r listMemberInstrumented) UpdateRole(ctx context.Context, userId, listId, memberId int, role string) (err error) {
+	ctx, done := r.begin(ctx, "ListMember", "UpdateRole")
+	defer done(&err)
+	return r.repo.UpdateRole(ctx, userId, listId, memberId, role)
+}
+
+func (r listMemberInstrumented) Delete(ctx context.Context, userId, listId, memberId int) (err error) {
+	ctx, done := r.begin(ctx, "ListMember", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, listId, memberId)
+}
+
+type invitationInstrumented struct {
+	repo Invitation
+	instrumentation
+}
+
+func (r invitationInstrumented) Create(ctx context.Context, userId, listId int, input model.InviteInput, expiresAt time.Time) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Invitation", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, listId, input, expiresAt)
+}
+
+func (r invitationInstrumented) GetPending(ctx context.Context, userId int) (_ []model.Invitation, err error) {
+	ctx, done := r.begin(ctx, "Invitation", "GetPending")
+	defer done(&err)
+	return r.repo.GetPending(ctx, userId)
+}
+
+func (r invitationInstrumented) Accept(ctx context.Context, userId, invitationId int) (err error) {
+	ctx, done := r.begin(ctx, "Invitation", "Accept")
+	defer done(&err)
+	return r.repo.Accept(ctx, userId, invitationId)
+}
+
+func (r invitationInstrumented) Decline(ctx context.Context, userId, invitationId int) (err error) {
+	ctx, done := r.begin(ctx, "Invitation", "Decline")
+	defer done(&err)
+	return r.repo.Decline(ctx, userId, invitationId)
+}
+
+type todoItemInstrumented struct {
+	repo TodoItem
+	instrumentation
+}
+
+func (r todoItemInstrumented) Create(ctx context.Context, userId, listId int, todoItem model.TodoItem) (_ int, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, listId, todoItem)
+}
+
+func (r todoItemInstrumented) CreateNextOccurrence(ctx context.Context, userId, itemId int, next model.TodoItem) (_ int, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "CreateNextOccurrence")
+	defer done(&err)
+	return r.repo.CreateNextOccurrence(ctx, userId, itemId, next)
+}
+
+func (r todoItemInstrumented) GetAll(ctx context.Context, userId, listId int, filter model.ItemFilter, page model.PageQuery) (_ []model.TodoItem, _ string, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId, listId, filter, page)
+}
+
+func (r todoItemInstrumented) Reorder(ctx context.Context, userId, listId int, input model.ReorderInput) (err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "Reorder")
+	defer done(&err)
+	return r.repo.Reorder(ctx, userId, listId, input)
+}
+
+func (r todoItemInstrumented) Move(ctx context.Context, userId, itemId, listId int) (err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "Move")
+	defer done(&err)
+	return r.repo.Move(ctx, userId, itemId, listId)
+}
+
+func (r todoItemInstrumented) Copy(ctx context.Context, userId, itemId, listId int) (_ int, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "Copy")
+	defer done(&err)
+	return r.repo.Copy(ctx, userId, itemId, listId)
+}
+
+func (r todoItemInstrumented) GetAllByUser(ctx context.Context, userId int, filter model.ItemFilter, page model.PageQuery) (_ []model.TodoItem, _ string, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "GetAllByUser")
+	defer done(&err)
+	return r.repo.GetAllByUser(ctx, userId, filter, page)
+}
+
+func (r todoItemInstrumented) GetAllByFilter(ctx context.Context, userId int, expr model.FilterExpr, page model.PageQuery) (_ []model.TodoItem, _ string, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "GetAllByFilter")
+	defer done(&err)
+	return r.repo.GetAllByFilter(ctx, userId, expr, page)
+}
+
+func (r todoItemInstrumented) GetById(ctx context.Context, userId, itemId int) (_ model.TodoItem, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "GetById")
+	defer done(&err)
+	return r.repo.GetById(ctx, userId, itemId)
+}
+
+func (r todoItemInstrumented) GetListId(ctx context.Context, userId, itemId int) (_ int, err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "GetListId")
+	defer done(&err)
+	return r.repo.GetListId(ctx, userId, itemId)
+}
+
+func (r todoItemInstrumented) Delete(ctx context.Context, userId, itemId int) (err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, itemId)
+}
+
+func (r todoItemInstrumented) Update(ctx context.Context, userId, listId int, input model.UpdateItemInput) (err error) {
+	ctx, done := r.begin(ctx, "TodoItem", "Update")
+	defer done(&err)
+	return r.repo.Update(ctx, userId, listId, input)
+}
+
+type commentInstrumented struct {
+	repo Comment
+	instrumentation
+}
+
+func (r commentInstrumented) Create(ctx context.Context, userId, itemId int, comment model.Comment, mentions []string) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Comment", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, itemId, comment, mentions)
+}
+
+func (r commentInstrumented) GetAll(ctx context.Context, userId, itemId int) (_ []model.Comment, err error) {
+	ctx, done := r.begin(ctx, "Comment", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId, itemId)
+}
+
+func (r commentInstrumented) Update(ctx context.Context, userId, itemId, commentId int, input model.UpdateCommentInput, mentions []string) (err error) {
+	ctx, done := r.begin(ctx, "Comment", "Update")
+	defer done(&err)
+	return r.repo.Update(ctx, userId, itemId, commentId, input, mentions)
+}
+
+func (r commentInstrumented) Delete(ctx context.Context, userId, itemId, commentId int) (err error) {
+	ctx, done := r.begin(ctx, "Comment", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, itemId, commentId)
+}
+
+type attachmentInstrumented struct {
+	repo Attachment
+	instrumentation
+}
+
+func (r attachmentInstrumented) CanWrite(ctx context.Context, userId, itemId int) (err error) {
+	ctx, done := r.begin(ctx, "Attachment", "CanWrite")
+	defer done(&err)
+	return r.repo.CanWrite(ctx, userId, itemId)
+}
+
+func (r attachmentInstrumented) Create(ctx context.Context, userId, itemId int, attachment model.Attachment) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Attachment", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, itemId, attachment)
+}
+
+func (r attachmentInstrumented) GetAll(ctx context.Context, userId, itemId int) (_ []model.Attachment, err error) {
+	ctx, done := r.begin(ctx, "Attachment", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId, itemId)
+}
+
+func (r attachmentInstrumented) GetById(ctx context.Context, userId, itemId, attachmentId int) (_ model.Attachment, err error) {
+	ctx, done := r.begin(ctx, "Attachment", "GetById")
+	defer done(&err)
+	return r.repo.GetById(ctx, userId, itemId, attachmentId)
+}
+
+func (r attachmentInstrumented) Delete(ctx context.Context, userId, itemId, attachmentId int) (_ string, err error) {
+	ctx, done := r.begin(ctx, "Attachment", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, itemId, attachmentId)
+}
+
+type labelInstrumented struct {
+	repo Label
+	instrumentation
+}
+
+func (r labelInstrumented) Create(ctx context.Context, userId int, label model.Label) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Label", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, label)
+}
+
+func (r labelInstrumented) GetAll(ctx context.Context, userId int) (_ []model.Label, err error) {
+	ctx, done := r.begin(ctx, "Label", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId)
+}
+
+func (r labelInstrumented) Update(ctx context.Context, userId, labelId int, input model.UpdateLabelInput) (err error) {
+	ctx, done := r.begin(ctx, "Label", "Update")
+	defer done(&err)
+	return r.repo.Update(ctx, userId, labelId, input)
+}
+
+func (r labelInstrumented) Delete(ctx context.Context, userId, labelId int) (err error) {
+	ctx, done := r.begin(ctx, "Label", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, labelId)
+}
+
+func (r labelInstrumented) Attach(ctx context.Context, userId, itemId, labelId int) (err error) {
+	ctx, done := r.begin(ctx, "Label", "Attach")
+	defer done(&err)
+	return r.repo.Attach(ctx, userId, itemId, labelId)
+}
+
+func (r labelInstrumented) Detach(ctx context.Context, userId, itemId, labelId int) (err error) {
+	ctx, done := r.begin(ctx, "Label", "Detach")
+	defer done(&err)
+	return r.repo.Detach(ctx, userId, itemId, labelId)
+}
+
+type subtaskInstrumented struct {
+	repo Subtask
+	instrumentation
+}
+
+func (r subtaskInstrumented) Create(ctx context.Context, userId, itemId int, subtask model.Subtask) (_ int, err error) {
+	ctx, done := r.begin(ctx, "Subtask", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, itemId, subtask)
+}
+
+func (r subtaskInstrumented) GetAll(ctx context.Context, userId, itemId int) (_ []model.Subtask, err error) {
+	ctx, done := r.begin(ctx, "Subtask", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId, itemId)
+}
+
+func (r subtaskInstrumented) Update(ctx context.Context, userId, itemId, subtaskId int, input model.UpdateSubtaskInput) (err error) {
+	ctx, done := r.begin(ctx, "Subtask", "Update")
+	defer done(&err)
+	return r.repo.Update(ctx, userId, itemId, subtaskId, input)
+}
+
+func (r subtaskInstrumented) Delete(ctx context.Context, userId, itemId, subtaskId int) (err error) {
+	ctx, done := r.begin(ctx, "Subtask", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, itemId, subtaskId)
+}
+
+func (r subtaskInstrumented) Reorder(ctx context.Context, userId, itemId int, ids []int) (err error) {
+	ctx, done := r.begin(ctx, "Subtask", "Reorder")
+	defer done(&err)
+	return r.repo.Reorder(ctx, userId, itemId, ids)
+}
+
+type savedFilterInstrumented struct {
+	repo SavedFilter
+	instrumentation
+}
+
+func (r savedFilterInstrumented) Create(ctx context.Context, userId int, filter model.SavedFilter) (_ int, err error) {
+	ctx, done := r.begin(ctx, "SavedFilter", "Create")
+	defer done(&err)
+	return r.repo.Create(ctx, userId, filter)
+}
+
+func (r savedFilterInstrumented) GetAll(ctx context.Context, userId int) (_ []model.SavedFilter, err error) {
+	ctx, done := r.begin(ctx, "SavedFilter", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId)
+}
+
+func (r savedFilterInstrumented) GetById(ctx context.Context, userId, filterId int) (_ model.SavedFilter, err error) {
+	ctx, done := r.begin(ctx, "SavedFilter", "GetById")
+	defer done(&err)
+	return r.repo.GetById(ctx, userId, filterId)
+}
+
+func (r savedFilterInstrumented) Update(ctx context.Context, userId, filterId int, input model.UpdateFilterInput) (err error) {
+	ctx, done := r.begin(ctx, "SavedFilter", "Update")
+	defer done(&err)
+	return r.repo.Update(ctx, userId, filterId, input)
+}
+
+func (r savedFilterInstrumented) Delete(ctx context.Context, userId, filterId int) (err error) {
+	ctx, done := r.begin(ctx, "SavedFilter", "Delete")
+	defer done(&err)
+	return r.repo.Delete(ctx, userId, filterId)
+}
+
+type searchInstrumented struct {
+	repo Search
+	instrumentation
+}
+
+func (r searchInstrumented) Search(ctx context.Context, userId int, query string, limit int) (_ []model.SearchResult, err error) {
+	ctx, done := r.begin(ctx, "Search", "Search")
+	defer done(&err)
+	return r.repo.Search(ctx, userId, query, limit)
+}
+
+// Configure may rebuild every search vector, so it is not bound by the query
+// timeout.
+func (r searchInstrumented) Configure(ctx context.Context) (err error) {
+	ctx, done := instrumentation{}.begin(ctx, "Search", "Configure")
+	defer done(&err)
+	return r.repo.Configure(ctx)
+}
+
+type trashInstrumented struct {
+	repo Trash
+	instrumentation
+}
+
+func (r trashInstrumented) GetAll(ctx context.Context, userId int) (_ []model.TrashEntry, err error) {
+	ctx, done := r.begin(ctx, "Trash", "GetAll")
+	defer done(&err)
+	return r.repo.GetAll(ctx, userId)
+}
+
+func (r trashInstrumented) Restore(ctx context.Context, userId int, entryType string, id int) (err error) {
+	ctx, done := r.begin(ctx, "Trash", "Restore")
+	defer done(&err)
+	return r.repo.Restore(ctx, userId, entryType, id)
+}
+
+func (r trashInstrumented) Purge(ctx context.Context, userId int, entryType string, id int) (_ []string, err error) {
+	ctx, done := r.begin(ctx, "Trash", "Purge")
+	defer done(&err)
+	return r.repo.Purge(ctx, userId, entryType, id)
+}
+
+func (r trashInstrumented) PurgeExpired(ctx context.Context, before time.Time) (_ int64, _ []string, err error) {
+	ctx, done := r.begin(ctx, "Trash", "PurgeExpired")
+	defer done(&err)
+	return r.repo.PurgeExpired(ctx, before)
+}
+
+type activityInstrumented struct {
+	repo Activity
+	instrumentation
+}
+
+func (r activityInstrumented) GetByList(ctx context.Context, userId, listId int, page model.PageQuery) (_ []model.Activity, _ string, err error) {
+	ctx, done := r.begin(ctx, "Activity", "GetByList")
+	defer done(&err)
+	return r.repo.GetByList(ctx, userId, listId, page)
+}
+
+func (r activityInstrumented) GetByItem(ctx context.Context, userId, itemId int, page model.PageQuery) (_ []model.Activity, _ string, err error) {
+	ctx, done := r.begin(ctx, "Activity", "GetByItem")
+	defer done(&err)
+	return r.repo.GetByItem(ctx, userId, itemId, page)
+}
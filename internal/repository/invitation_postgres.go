@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type InvitationPostgres struct {
+	db *sqlx.DB
+}
+
+func NewInvitationPostgres(db *sqlx.DB) *InvitationPostgres {
+	return &InvitationPostgres{db: db}
+}
+
+func (r *InvitationPostgres) Create(ctx context.Context, userId, listId int, input model.InviteInput, expiresAt time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	role, err := getListRole(ctx, tx, userId, listId)
+	if err == nil {
+		err = requireRole(role, model.RoleOwner)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var inviteeId int
+	userQuery := fmt.Sprintf("SELECT id FROM %s WHERE username = $1", usersTable)
+	if err = tx.GetContext(ctx, &inviteeId, userQuery, input.Username); err != nil {
+		_ = tx.Rollback()
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, model.ErrUserNotFound
+		}
+		return 0, err
+	}
+
+	if _, err = getListRole(ctx, tx, inviteeId, listId); err == nil {
+		_ = tx.Rollback()
+		return 0, model.ErrAlreadyMember
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	// an expired invitation must not block a new one
+	expireQuery := fmt.Sprintf("UPDATE %s SET status = $1, responded_at = NOW() WHERE list_id = $2 AND invitee_id = $3 AND status = $4 AND expires_at <= NOW()",
+		listInvitationsTable)
+	if _, err = tx.ExecContext(ctx, expireQuery, model.InvitationExpired, listId, inviteeId, model.InvitationPending); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var id int
+	createQuery := fmt.Sprintf("INSERT INTO %s (list_id, inviter_id, invitee_id, role, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		listInvitationsTable)
+	if err = tx.QueryRowContext(ctx, createQuery, listId, userId, inviteeId, input.Role, expiresAt).Scan(&id); err != nil {
+		_ = tx.Rollback()
+		if isUniqueViolation(err) {
+			return 0, model.ErrAlreadyInvited
+		}
+		return 0, err
+	}
+
+	changes := model.Changes{model.MemberField(inviteeId): {Old: nil, New: input.Role}}
+	if err = logActivity(ctx, tx, userId, model.EntityList, listId, listId, model.ActionInvited, changes); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *InvitationPostgres) GetPending(ctx context.Context, userId int) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	query := fmt.Sprintf(`SELECT inv.id, inv.list_id, tl.title AS list_title, u.username AS inviter_username, inv.role, inv.status, inv.created_at, inv.expires_at
+									FROM %s inv INNER JOIN %s tl ON tl.id = inv.list_id INNER JOIN %s u ON u.id = inv.inviter_id
+									WHERE inv.invitee_id = $1 AND inv.status = $2 AND inv.expires_at > NOW() AND tl.deleted_at IS NULL ORDER BY inv.created_at`,
+		listInvitationsTable, todoListsTable, usersTable)
+	err := r.db.SelectContext(ctx, &invitations, query, userId, model.InvitationPending)
+	return invitations, err
+}
+
+// Accept makes the user a member of the list with the invited role. The list
+// is only shared in its history if the user was not a member already.
+func (r *InvitationPostgres) Accept(ctx context.Context, userId, invitationId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := r.respond(ctx, tx, userId, invitationId, model.InvitationAccepted)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	// a list in the trash has no invitations to accept, like in GetPending
+	var active bool
+	listQuery := fmt.Sprintf("SELECT deleted_at IS NULL FROM %s WHERE id = $1 FOR SHARE", todoListsTable)
+	if err = tx.GetContext(ctx, &active, listQuery, invitation.ListId); err == nil && !active {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	memberQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id, role, position) VALUES ($1, $2, $3, %s) ON CONFLICT (user_id, list_id) DO NOTHING",
+		usersListsTable, listPositions.last("$1"))
+	res, err := tx.ExecContext(ctx, memberQuery, userId, invitation.ListId, invitation.Role)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	inserted, err := res.RowsAffected()
+	if err == nil && inserted > 0 {
+		changes := model.Changes{model.MemberField(userId): {Old: nil, New: invitation.Role}}
+		err = logActivity(ctx, tx, userId, model.EntityList, invitation.ListId, invitation.ListId, model.ActionShared, changes)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *InvitationPostgres) Decline(ctx context.Context, userId, invitationId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.respond(ctx, tx, userId, invitationId, model.InvitationDeclined); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// respond locks a pending invitation of the user and sets its final status.
+func (r *InvitationPostgres) respond(ctx context.Context, tx *sqlx.Tx, userId, invitationId int, status string) (model.Invitation, error) {
+	var invitation model.Invitation
+	selectQuery := fmt.Sprintf("SELECT id, list_id, role, status, created_at, expires_at FROM %s WHERE id = $1 AND invitee_id = $2 AND status = $3 FOR UPDATE",
+		listInvitationsTable)
+	if err := tx.GetContext(ctx, &invitation, selectQuery, invitationId, userId, model.InvitationPending); err != nil {
+		return invitation, err
+	}
+
+	if !invitation.ExpiresAt.After(time.Now()) {
+		return invitation, model.ErrInvitationExpired
+	}
+
+	updateQuery := fmt.Sprintf("UPDATE %s SET status = $1, responded_at = NOW() WHERE id = $2", listInvitationsTable)
+	_, err := tx.ExecContext(ctx, updateQuery, status, invitationId)
+	return invitation, err
+}
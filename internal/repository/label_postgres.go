@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+type LabelPostgres struct {
+	db *sqlx.DB
+}
+
+func NewLabelPostgres(db *sqlx.DB) *LabelPostgres {
+	return &LabelPostgres{db: db}
+}
+
+func (r *LabelPostgres) Create(ctx context.Context, userId int, label model.Label) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, name, color) VALUES ($1, $2, $3) RETURNING id", labelsTable)
+	if err := r.db.QueryRowContext(ctx, query, userId, label.Name, label.Color).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, model.ErrLabelExists
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *LabelPostgres) GetAll(ctx context.Context, userId int) ([]model.Label, error) {
+	var labels []model.Label
+	query := fmt.Sprintf("SELECT id, name, color, created_at FROM %s WHERE user_id = $1 ORDER BY name", labelsTable)
+	err := r.db.SelectContext(ctx, &labels, query, userId)
+	return labels, err
+}
+
+func (r *LabelPostgres) Update(ctx context.Context, userId, labelId int, input model.UpdateLabelInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name=$%d", argId))
+		args = append(args, *input.Name)
+		argId++
+	}
+
+	if input.Color != nil {
+		setValues = append(setValues, fmt.Sprintf("color=$%d", argId))
+		args = append(args, *input.Color)
+		argId++
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE user_id = $%d AND id = $%d", labelsTable, strings.Join(setValues, ", "), argId, argId+1)
+	args = append(args, userId, labelId)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.ErrLabelExists
+		}
+		return err
+	}
+
+	return checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+}
+
+func (r *LabelPostgres) Delete(ctx context.Context, userId, labelId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND id = $2", labelsTable)
+	res, err := r.db.ExecContext(ctx, query, userId, labelId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+}
+
+// Attach adds a label of the user to an item the user may change.
+func (r *LabelPostgres) Attach(ctx context.Context, userId, itemId, labelId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (item_id, label_id) SELECT $1, l.id FROM %s l WHERE l.id = $2 AND l.user_id = $3
+									ON CONFLICT (item_id, label_id) DO NOTHING`, itemsLabelsTable, labelsTable)
+	res, err := tx.ExecContext(ctx, query, itemId, labelId, userId)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		// nothing inserted: either the label is already attached or it is not the user's label
+		var exists bool
+		existsQuery := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND user_id = $2)", labelsTable)
+		if err = tx.GetContext(ctx, &exists, existsQuery, labelId, userId); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if !exists {
+			_ = tx.Rollback()
+			return model.ErrLabelNotFound
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Detach removes one of the user's labels from an item. Labels of other users
+// can not be seen and so can not be removed either.
+func (r *LabelPostgres) Detach(ctx context.Context, userId, itemId, labelId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE item_id = $1 AND label_id = $2 AND label_id IN (SELECT id FROM %s WHERE user_id = $3)",
+		itemsLabelsTable, labelsTable)
+	res, err := tx.ExecContext(ctx, query, itemId, labelId, userId)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if affected == 0 {
+		// nothing deleted: either the label is not attached or it is not the user's label
+		var exists bool
+		existsQuery := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND user_id = $2)", labelsTable)
+		if err = tx.GetContext(ctx, &exists, existsQuery, labelId, userId); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if !exists {
+			_ = tx.Rollback()
+			return model.ErrLabelNotFound
+		}
+	}
+
+	return tx.Commit()
+}
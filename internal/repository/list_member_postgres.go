@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+type ListMemberPostgres struct {
+	db *sqlx.DB
+}
+
+func NewListMemberPostgres(db *sqlx.DB) *ListMemberPostgres {
+	return &ListMemberPostgres{db: db}
+}
+
+// GetAll returns the members of a list the user is a member of. Lists in the
+// trash have no members to show.
+func (r *ListMemberPostgres) GetAll(ctx context.Context, userId, listId int) ([]model.ListMember, error) {
+	var members []model.ListMember
+	query := fmt.Sprintf(`SELECT u.id AS user_id, u.name, u.username, ul.role FROM %s ul INNER JOIN %s u ON u.id = ul.user_id
+									INNER JOIN %s tl ON tl.id = ul.list_id
+									WHERE ul.list_id = $1 AND tl.deleted_at IS NULL AND EXISTS (SELECT 1 FROM %s me WHERE me.list_id = ul.list_id AND me.user_id = $2)
+									ORDER BY u.id`, usersListsTable, usersTable, todoListsTable, usersListsTable)
+	err := r.db.SelectContext(ctx, &members, query, listId, userId)
+	return members, err
+}
+
+func (r *ListMemberPostgres) UpdateRole(ctx context.Context, userId, listId, memberId int, role string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = r.requireOwner(ctx, tx, userId, listId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if role != model.RoleOwner {
+		if err = r.keepOwner(ctx, tx, listId, memberId); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	oldRole, err := getListRole(ctx, tx, memberId, listId)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET role = $1 WHERE list_id = $2 AND user_id = $3", usersListsTable)
+	res, err := tx.ExecContext(ctx, query, role, listId, memberId)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+	}
+	if err == nil && role != oldRole {
+		changes := model.Changes{model.MemberField(memberId): {Old: oldRole, New: role}}
+		err = logActivity(ctx, tx, userId, model.EntityList, listId, listId, model.ActionShared, changes)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a member from the list. Owners may remove anyone, every other
+// member may only leave the list.
+func (r *ListMemberPostgres) Delete(ctx context.Context, userId, listId, memberId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if userId != memberId {
+		err = r.requireOwner(ctx, tx, userId, listId)
+	}
+	if err == nil {
+		err = r.keepOwner(ctx, tx, listId, memberId)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE list_id = $1 AND user_id = $2 RETURNING role", usersListsTable)
+	var oldRole string
+	err = tx.GetContext(ctx, &oldRole, query, listId, memberId)
+	if err == nil {
+		changes := model.Changes{model.MemberField(memberId): {Old: oldRole, New: nil}}
+		err = logActivity(ctx, tx, userId, model.EntityList, listId, listId, model.ActionUnshared, changes)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ListMemberPostgres) requireOwner(ctx context.Context, tx *sqlx.Tx, userId, listId int) error {
+	role, err := getListRole(ctx, tx, userId, listId)
+	if err != nil {
+		return err
+	}
+
+	return requireRole(role, model.RoleOwner)
+}
+
+// keepOwner fails when memberId is the only owner of the list. The owner rows
+// are locked, so two owners can not demote each other at the same time.
+func (r *ListMemberPostgres) keepOwner(ctx context.Context, tx *sqlx.Tx, listId, memberId int) error {
+	var owners []int
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE list_id = $1 AND role = $2 FOR UPDATE", usersListsTable)
+	if err := tx.SelectContext(ctx, &owners, query, listId, model.RoleOwner); err != nil {
+		return err
+	}
+
+	if len(owners) == 1 && owners[0] == memberId {
+		return model.ErrLastOwner
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortColumn is an expression a collection can be sorted by and the SQL type
+// its values are cast back to from a cursor. Expressions may differ by
+// direction, so that NULLs come last either way.
+type sortColumn struct {
+	asc     string
+	desc    string
+	sqlType string
+}
+
+var priorityRank = fmt.Sprintf("CASE ti.priority WHEN '%s' THEN 1 WHEN '%s' THEN 2 WHEN '%s' THEN 3 WHEN '%s' THEN 4 ELSE 0 END",
+	model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityUrgent)
+
+var itemSorts = map[string]sortColumn{
+	"position":   {asc: "li.position", sqlType: "bigint"},
+	"created_at": {asc: "ti.created_at", sqlType: "timestamptz"},
+	"due_at":     {asc: "COALESCE(ti.due_at, 'infinity')", desc: "COALESCE(ti.due_at, '-infinity')", sqlType: "timestamptz"},
+	"title":      {asc: "ti.title", sqlType: "text"},
+	"priority":   {asc: priorityRank, sqlType: "int"},
+}
+
+var listSorts = map[string]sortColumn{
+	"position":   {asc: "ul.position", sqlType: "bigint"},
+	"created_at": {asc: "tl.created_at", sqlType: "timestamptz"},
+	"title":      {asc: "COALESCE(tl.title, '')", sqlType: "text"},
+}
+
+// cursor points right after the last row of a page by its sort value and id.
+// It records the sort so that it is not used with another one.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    int    `json:"id"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, model.ErrInvalidCursor
+	}
+	if err = json.Unmarshal(data, &c); err != nil {
+		return c, model.ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// keyset is a page query resolved against the sortable columns of a
+// collection. Rows are ordered by the sort expression and then by id, so the
+// order is total and a cursor can resume right after any row.
+type keyset struct {
+	sort    string
+	expr    string
+	order   string
+	sqlType string
+	id      string
+	limit   int
+	after   *cursor
+}
+
+func newKeyset(sorts map[string]sortColumn, page model.PageQuery, defaultSort, idColumn string) (keyset, error) {
+	k := keyset{sort: page.Sort, id: idColumn, limit: page.Limit}
+	if k.sort == "" {
+		k.sort = defaultSort
+	}
+	if k.limit == 0 {
+		k.limit = model.DefaultPageLimit
+	}
+
+	name := strings.TrimPrefix(k.sort, "-")
+	column, ok := sorts[name]
+	if !ok {
+		return k, model.ErrInvalidSort
+	}
+
+	k.expr, k.order, k.sqlType = column.asc, "ASC", column.sqlType
+	if name != k.sort {
+		k.order = "DESC"
+		if column.desc != "" {
+			k.expr = column.desc
+		}
+	}
+
+	if page.Cursor != "" {
+		after, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return k, err
+		}
+		if after.Sort != k.sort || !validCursorValue(k.sqlType, after.Value) {
+			return k, model.ErrInvalidCursor
+		}
+		k.after = &after
+	}
+
+	return k, nil
+}
+
+// timestampLayouts are the ISO outputs of Postgres for timestamptz values,
+// which differ in the precision of the offset.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+}
+
+// validCursorValue reports whether a cursor value can be cast back to
+// sqlType, so that a tampered cursor is rejected instead of failing the query.
+func validCursorValue(sqlType, value string) bool {
+	switch sqlType {
+	case "bigint":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case "int":
+		_, err := strconv.ParseInt(value, 10, 32)
+		return err == nil
+	case "timestamptz":
+		if value == "infinity" || value == "-infinity" {
+			return true
+		}
+		value = strings.TrimSuffix(value, " BC")
+		for _, layout := range timestampLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				return true
+			}
+		}
+		return false
+	default:
+		// text can hold anything but NUL
+		return !strings.ContainsRune(value, 0)
+	}
+}
+
+// condition selects the rows after the cursor, using placeholders from argId
+// on. It is empty for the first page.
+func (k keyset) condition(argId int) (string, []interface{}) {
+	if k.after == nil {
+		return "", nil
+	}
+
+	comparison := ">"
+	if k.order == "DESC" {
+		comparison = "<"
+	}
+
+	condition := fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d)", k.expr, k.id, comparison, argId, k.sqlType, argId+1)
+	return condition, []interface{}{k.after.Value, k.after.Id}
+}
+
+// column selects the sort value as sort_key, which next needs.
+func (k keyset) column() string {
+	return fmt.Sprintf("(%s)::text AS sort_key", k.expr)
+}
+
+func (k keyset) orderBy() string {
+	return fmt.Sprintf("%s %s, %s %s", k.expr, k.order, k.id, k.order)
+}
+
+// fetch is the number of rows to select: one more than the limit tells
+// whether there is a next page.
+func (k keyset) fetch() int {
+	return k.limit + 1
+}
+
+// next returns the cursor of the following page given the number of fetched
+// rows and the sort key and id of the last row of the page, or "" if this is
+// the last page.
+func (k keyset) next(fetched int, sortKey string, id int) string {
+	if fetched <= k.limit {
+		return ""
+	}
+	return cursor{Sort: k.sort, Value: sortKey, Id: id}.encode()
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern is an ILIKE pattern matching s literally anywhere.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
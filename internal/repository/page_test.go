@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"errors"
+	"testing"
+)
+
+func TestNewKeysetCursor(t *testing.T) {
+	tests := []struct {
+		name  string
+		sort  string
+		value string
+		valid bool
+	}{
+		{"bigint", "position", "1024", true},
+		{"bigint negative", "position", "-1024", true},
+		{"bigint text", "position", "abc", false},
+		{"bigint overflow", "position", "9223372036854775808", false},
+		{"int", "priority", "3", true},
+		{"int float", "priority", "3.5", false},
+		{"timestamptz utc", "created_at", "2024-05-10 12:30:00.123456+00", true},
+		{"timestamptz whole seconds", "created_at", "2024-05-10 12:30:00+00", true},
+		{"timestamptz minute offset", "created_at", "2024-05-10 12:30:00+05:30", true},
+		{"timestamptz second offset", "created_at", "1890-05-10 12:30:00+00:53:28", true},
+		{"timestamptz infinity", "due_at", "infinity", true},
+		{"timestamptz minus infinity", "-due_at", "-infinity", true},
+		{"timestamptz garbage", "created_at", "yesterday", false},
+		{"timestamptz out of range", "created_at", "2024-02-30 12:30:00+00", false},
+		{"text", "title", "Groceries", true},
+		{"text empty", "title", "", true},
+		{"text nul", "title", "a\x00b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := model.PageQuery{Sort: tt.sort, Cursor: cursor{Sort: tt.sort, Value: tt.value, Id: 1}.encode()}
+			_, err := newKeyset(itemSorts, page, "position", "ti.id")
+			if tt.valid && err != nil {
+				t.Errorf("newKeyset() error: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, model.ErrInvalidCursor) {
+				t.Errorf("newKeyset() error = %v, want %v", err, model.ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestNewKeysetRejectsCursorOfOtherSort(t *testing.T) {
+	page := model.PageQuery{Sort: "title", Cursor: cursor{Sort: "-title", Value: "a", Id: 1}.encode()}
+	if _, err := newKeyset(itemSorts, page, "position", "ti.id"); !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("newKeyset() error = %v, want %v", err, model.ErrInvalidCursor)
+	}
+}
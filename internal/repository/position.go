@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+// positionGap separates neighbouring positions, so most moves only write the
+// moved row: it takes the midpoint of its new neighbours. A scope is renumbered
+// when two neighbours have no gap left between them.
+const positionGap = 1024
+
+// positionScope describes ordered rows, such as the items of a list or the
+// lists of a user.
+type positionScope struct {
+	table       string
+	scopeColumn string
+	idColumn    string
+}
+
+var (
+	itemPositions = positionScope{table: listsItemsTable, scopeColumn: "list_id", idColumn: "item_id"}
+	listPositions = positionScope{table: usersListsTable, scopeColumn: "user_id", idColumn: "list_id"}
+)
+
+// last is an expression for the position after the last row of the scope, for
+// use in INSERT statements. arg is the placeholder of the scope id.
+func (p positionScope) last(arg string) string {
+	return fmt.Sprintf("(SELECT COALESCE(MAX(position), 0) + %d FROM %s WHERE %s = %s)", positionGap, p.table, p.scopeColumn, arg)
+}
+
+// move places the row right before or after the anchor given by input. Rows
+// of the scope are locked until tx ends.
+func (p positionScope) move(ctx context.Context, tx *sqlx.Tx, scopeId int, input model.ReorderInput) error {
+	lockQuery := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1 FOR UPDATE", p.idColumn, p.table, p.scopeColumn)
+	var ids []int
+	if err := tx.SelectContext(ctx, &ids, lockQuery, scopeId); err != nil {
+		return err
+	}
+
+	if !containsId(ids, input.Id) {
+		return sql.ErrNoRows
+	}
+
+	anchorId, before := 0, input.BeforeId != nil
+	if before {
+		anchorId = *input.BeforeId
+	} else {
+		anchorId = *input.AfterId
+	}
+
+	if !containsId(ids, anchorId) {
+		return model.ErrAnchorNotFound
+	}
+
+	position, err := p.between(ctx, tx, scopeId, input.Id, anchorId, before)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET position = $1 WHERE %s = $2 AND %s = $3", p.table, p.scopeColumn, p.idColumn)
+	_, err = tx.ExecContext(ctx, query, position, scopeId, input.Id)
+	return err
+}
+
+// between finds a free position next to the anchor, renumbering the scope once
+// if the anchor and its neighbour are adjacent.
+func (p positionScope) between(ctx context.Context, tx *sqlx.Tx, scopeId, id, anchorId int, before bool) (int64, error) {
+	for renumbered := false; ; renumbered = true {
+		var anchor int64
+		anchorQuery := fmt.Sprintf("SELECT position FROM %s WHERE %s = $1 AND %s = $2", p.table, p.scopeColumn, p.idColumn)
+		if err := tx.GetContext(ctx, &anchor, anchorQuery, scopeId, anchorId); err != nil {
+			return 0, err
+		}
+
+		// the nearest other row on the side the moved row goes to
+		comparison, order, edge := ">", "ASC", anchor+2*positionGap
+		if before {
+			comparison, order, edge = "<", "DESC", anchor-2*positionGap
+		}
+
+		var neighbour int64
+		neighbourQuery := fmt.Sprintf("SELECT position FROM %s WHERE %s = $1 AND %s <> $2 AND position %s $3 ORDER BY position %s LIMIT 1",
+			p.table, p.scopeColumn, p.idColumn, comparison, order)
+		err := tx.GetContext(ctx, &neighbour, neighbourQuery, scopeId, id, anchor)
+		if errors.Is(err, sql.ErrNoRows) {
+			neighbour = edge
+		} else if err != nil {
+			return 0, err
+		}
+
+		if gap := neighbour - anchor; gap > 1 || gap < -1 || renumbered {
+			return anchor + (neighbour-anchor)/2, nil
+		}
+
+		if err = p.renumber(ctx, tx, scopeId); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// renumber spreads the rows of the scope positionGap apart, keeping their order.
+func (p positionScope) renumber(ctx context.Context, tx *sqlx.Tx, scopeId int) error {
+	query := fmt.Sprintf(`UPDATE %[1]s t SET position = o.position
+									FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position, %[2]s) * %[3]d AS position FROM %[1]s WHERE %[4]s = $1) o
+									WHERE t.id = o.id`, p.table, p.idColumn, positionGap, p.scopeColumn)
+	_, err := tx.ExecContext(ctx, query, scopeId)
+	return err
+}
+
+func containsId(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1,16 +1,32 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
 const (
-	usersTable      = "users"
-	todoListsTable  = "todo_lists"
-	usersListsTable = "users_lists"
-	todoItemsTable  = "todo_items"
-	listsItemsTable = "lists_items"
+	usersTable                = "users"
+	todoListsTable            = "todo_lists"
+	usersListsTable           = "users_lists"
+	todoItemsTable            = "todo_items"
+	listsItemsTable           = "lists_items"
+	sessionsTable             = "sessions"
+	personalAccessTokensTable = "personal_access_tokens"
+	listInvitationsTable      = "list_invitations"
+	labelsTable               = "labels"
+	itemsLabelsTable          = "items_labels"
+	subtasksTable             = "subtasks"
+	savedFiltersTable         = "saved_filters"
+	activityLogTable          = "activity_log"
+	commentsTable             = "comments"
+	attachmentsTable          = "attachments"
+	rotatedRefreshTokensTable = "rotated_refresh_tokens"
+	searchSettingsTable       = "search_settings"
 )
 
 type Config struct {
@@ -22,14 +38,19 @@ type Config struct {
 	SSLMode  string
 }
 
+// NewPostgresDB opens the database through a driver that traces every
+// statement run with a context.
 func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres",
+	sqlDB, err := otelsql.Open("postgres",
 		fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-			cfg.Host, cfg.Port, cfg.UserName, cfg.DBName, cfg.Password, cfg.SSLMode))
+			cfg.Host, cfg.Port, cfg.UserName, cfg.DBName, cfg.Password, cfg.SSLMode),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{DisableErrSkip: true, OmitConnResetSession: true, OmitRows: true}))
 
 	if err != nil {
 		return nil, err
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
 	err = db.Ping()
 	if err != nil {
@@ -38,3 +59,8 @@ func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -2,40 +2,172 @@ package repository
 
 import (
 	"TodoApp/internal/model"
+	"context"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 type Authorization interface {
-	CreateUser(model.User) (int, error)
-	GetUser(username, password string) (model.User, error)
+	CreateUser(ctx context.Context, user model.User) (int, error)
+	GetUser(ctx context.Context, username string) (model.User, error)
+	UpdatePasswordHash(ctx context.Context, userId int, passwordHash string) error
+}
+
+type Session interface {
+	Create(ctx context.Context, userId int, refreshTokenHash string, expiresAt time.Time) (int, error)
+	GetById(ctx context.Context, sessionId int) (model.Session, error)
+	GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (model.Session, error)
+	Rotate(ctx context.Context, sessionId int, oldTokenHash, refreshTokenHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, sessionId int) error
+	RevokeRotated(ctx context.Context, refreshTokenHash string) (bool, error)
+}
+
+type Token interface {
+	Create(ctx context.Context, token model.PersonalAccessToken) (model.PersonalAccessToken, error)
+	GetAll(ctx context.Context, userId int) ([]model.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userId, tokenId int) error
+	Use(ctx context.Context, tokenHash string) (model.PersonalAccessToken, error)
 }
 
 type TodoList interface {
-	Create(userId int, list model.TodoList) (int, error)
-	GetAll(userId int) ([]model.TodoList, error)
-	GetById(userId, listId int) (model.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input model.UpdateListInput) error
+	Create(ctx context.Context, userId int, list model.TodoList) (int, error)
+	GetAll(ctx context.Context, userId int, filter model.ListFilter, page model.PageQuery) ([]model.TodoList, string, error)
+	GetById(ctx context.Context, userId, listId int) (model.TodoList, error)
+	Reorder(ctx context.Context, userId int, input model.ReorderInput) error
+	SetArchived(ctx context.Context, userId, listId int, archived bool) error
+	Delete(ctx context.Context, userId, listId int) error
+	Update(ctx context.Context, userId, listId int, input model.UpdateListInput) error
+}
+
+type ListMember interface {
+	GetAll(ctx context.Context, userId, listId int) ([]model.ListMember, error)
+	UpdateRole(ctx context.Context, userId, listId, memberId int, role string) error
+	Delete(ctx context.Context, userId, listId, memberId int) error
+}
+
+type Invitation interface {
+	Create(ctx context.Context, userId, listId int, input model.InviteInput, expiresAt time.Time) (int, error)
+	GetPending(ctx context.Context, userId int) ([]model.Invitation, error)
+	Accept(ctx context.Context, userId, invitationId int) error
+	Decline(ctx context.Context, userId, invitationId int) error
 }
 
 type TodoItem interface {
-	Create(listId int, todoItem model.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]model.TodoItem, error)
-	GetById(userId, itemId int) (model.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, listId int, input model.UpdateItemInput) error
+	Create(ctx context.Context, userId, listId int, todoItem model.TodoItem) (int, error)
+	CreateNextOccurrence(ctx context.Context, userId, itemId int, next model.TodoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error)
+	Reorder(ctx context.Context, userId, listId int, input model.ReorderInput) error
+	Move(ctx context.Context, userId, itemId, listId int) error
+	Copy(ctx context.Context, userId, itemId, listId int) (int, error)
+	GetAllByUser(ctx context.Context, userId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error)
+	GetAllByFilter(ctx context.Context, userId int, expr model.FilterExpr, page model.PageQuery) ([]model.TodoItem, string, error)
+	GetById(ctx context.Context, userId, itemId int) (model.TodoItem, error)
+	GetListId(ctx context.Context, userId, itemId int) (int, error)
+	Delete(ctx context.Context, userId, itemId int) error
+	Update(ctx context.Context, userId, listId int, input model.UpdateItemInput) error
+}
+
+type Comment interface {
+	Create(ctx context.Context, userId, itemId int, comment model.Comment, mentions []string) (int, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]model.Comment, error)
+	Update(ctx context.Context, userId, itemId, commentId int, input model.UpdateCommentInput, mentions []string) error
+	Delete(ctx context.Context, userId, itemId, commentId int) error
+}
+
+type Attachment interface {
+	CanWrite(ctx context.Context, userId, itemId int) error
+	Create(ctx context.Context, userId, itemId int, attachment model.Attachment) (int, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]model.Attachment, error)
+	GetById(ctx context.Context, userId, itemId, attachmentId int) (model.Attachment, error)
+	Delete(ctx context.Context, userId, itemId, attachmentId int) (string, error)
+}
+
+type Label interface {
+	Create(ctx context.Context, userId int, label model.Label) (int, error)
+	GetAll(ctx context.Context, userId int) ([]model.Label, error)
+	Update(ctx context.Context, userId, labelId int, input model.UpdateLabelInput) error
+	Delete(ctx context.Context, userId, labelId int) error
+	Attach(ctx context.Context, userId, itemId, labelId int) error
+	Detach(ctx context.Context, userId, itemId, labelId int) error
+}
+
+type Subtask interface {
+	Create(ctx context.Context, userId, itemId int, subtask model.Subtask) (int, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]model.Subtask, error)
+	Update(ctx context.Context, userId, itemId, subtaskId int, input model.UpdateSubtaskInput) error
+	Delete(ctx context.Context, userId, itemId, subtaskId int) error
+	Reorder(ctx context.Context, userId, itemId int, ids []int) error
+}
+
+type SavedFilter interface {
+	Create(ctx context.Context, userId int, filter model.SavedFilter) (int, error)
+	GetAll(ctx context.Context, userId int) ([]model.SavedFilter, error)
+	GetById(ctx context.Context, userId, filterId int) (model.SavedFilter, error)
+	Update(ctx context.Context, userId, filterId int, input model.UpdateFilterInput) error
+	Delete(ctx context.Context, userId, filterId int) error
+}
+
+type Search interface {
+	Search(ctx context.Context, userId int, query string, limit int) ([]model.SearchResult, error)
+	Configure(ctx context.Context) error
+}
+
+type Trash interface {
+	GetAll(ctx context.Context, userId int) ([]model.TrashEntry, error)
+	Restore(ctx context.Context, userId int, entryType string, id int) error
+	Purge(ctx context.Context, userId int, entryType string, id int) ([]string, error)
+	PurgeExpired(ctx context.Context, before time.Time) (int64, []string, error)
+}
+
+type Health interface {
+	Ping(ctx context.Context) error
+}
+
+type Activity interface {
+	GetByList(ctx context.Context, userId, listId int, page model.PageQuery) ([]model.Activity, string, error)
+	GetByItem(ctx context.Context, userId, itemId int, page model.PageQuery) ([]model.Activity, string, error)
 }
 
 type Repository struct {
 	Authorization
+	Session
+	Token
 	TodoList
+	ListMember
+	Invitation
 	TodoItem
+	Subtask
+	Comment
+	Attachment
+	Label
+	SavedFilter
+	Search
+	Trash
+	Activity
+	Health
 }
 
-func NewRepository(db *sqlx.DB) *Repository {
+// NewRepository bounds every call with queryTimeout, no limit if it is 0.
+// Search.Configure builds the search vectors with the text search
+// configuration searchConfig.
+func NewRepository(db *sqlx.DB, queryTimeout time.Duration, searchConfig string) *Repository {
+	i := instrumentation{timeout: queryTimeout}
 	return &Repository{
-		Authorization: NewAuthPostgres(db),
-		TodoList:      NewTodoListPostgres(db),
-		TodoItem:      NewTodoItemRepository(db),
+		Authorization: authorizationInstrumented{NewAuthPostgres(db), i},
+		Session:       sessionInstrumented{NewSessionPostgres(db), i},
+		Token:         tokenInstrumented{NewTokenPostgres(db), i},
+		TodoList:      todoListInstrumented{NewTodoListPostgres(db), i},
+		ListMember:    listMemberInstrumented{NewListMemberPostgres(db), i},
+		Invitation:    invitationInstrumented{NewInvitationPostgres(db), i},
+		TodoItem:      todoItemInstrumented{NewTodoItemRepository(db), i},
+		Subtask:       subtaskInstrumented{NewSubtaskPostgres(db), i},
+		Comment:       commentInstrumented{NewCommentPostgres(db), i},
+		Attachment:    attachmentInstrumented{NewAttachmentPostgres(db), i},
+		Label:         labelInstrumented{NewLabelPostgres(db), i},
+		SavedFilter:   savedFilterInstrumented{NewSavedFilterPostgres(db), i},
+		Search:        searchInstrumented{NewSearchPostgres(db, searchConfig), i},
+		Trash:         trashInstrumented{NewTrashPostgres(db), i},
+		Activity:      activityInstrumented{NewActivityPostgres(db), i},
+		Health:        NewHealthPostgres(db),
 	}
 }
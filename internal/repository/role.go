@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+// writeRoles may change the items of a list. Only owners may change the list
+// itself and its members.
+var writeRoles = fmt.Sprintf("('%s', '%s')", model.RoleOwner, model.RoleEditor)
+
+// getListRole returns sql.ErrNoRows for lists in the trash, as if they were
+// gone, so every check built on it ignores them.
+func getListRole(ctx context.Context, q sqlx.QueryerContext, userId, listId int) (string, error) {
+	var role string
+	query := fmt.Sprintf("SELECT ul.role FROM %s ul INNER JOIN %s tl ON tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL",
+		usersListsTable, todoListsTable)
+	err := sqlx.GetContext(ctx, q, &role, query, userId, listId)
+	return role, err
+}
+
+// getItemRole returns sql.ErrNoRows for items in the trash. Items of a list in
+// the trash are in the trash too.
+func getItemRole(ctx context.Context, q sqlx.QueryerContext, userId, itemId int) (string, error) {
+	var role string
+	query := fmt.Sprintf(`SELECT ul.role FROM %s ul INNER JOIN %s li ON li.list_id = ul.list_id INNER JOIN %s ti ON ti.id = li.item_id
+									WHERE ul.user_id = $1 AND li.item_id = $2 AND ti.deleted_at IS NULL`,
+		usersListsTable, listsItemsTable, todoItemsTable)
+	err := sqlx.GetContext(ctx, q, &role, query, userId, itemId)
+	return role, err
+}
+
+func requireListWrite(ctx context.Context, q sqlx.QueryerContext, userId, listId int) error {
+	role, err := getListRole(ctx, q, userId, listId)
+	if err != nil {
+		return err
+	}
+
+	return requireRole(role, model.RoleOwner, model.RoleEditor)
+}
+
+func requireItemWrite(ctx context.Context, q sqlx.QueryerContext, userId, itemId int) error {
+	role, err := getItemRole(ctx, q, userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	return requireRole(role, model.RoleOwner, model.RoleEditor)
+}
+
+// requireActiveListTx returns model.ErrListArchived for archived lists, whose
+// items are read-only. The list is share-locked, so it can not be archived
+// before the transaction of the write ends.
+func requireActiveListTx(ctx context.Context, tx *sqlx.Tx, listId int) error {
+	var archived bool
+	query := fmt.Sprintf("SELECT archived FROM %s WHERE id = $1 FOR SHARE", todoListsTable)
+	if err := tx.GetContext(ctx, &archived, query, listId); err != nil {
+		return err
+	}
+
+	if archived {
+		return model.ErrListArchived
+	}
+
+	return nil
+}
+
+// requireActiveItemTx checks the list of the item like requireActiveListTx and
+// locks the item in its list as well.
+func requireActiveItemTx(ctx context.Context, tx *sqlx.Tx, itemId int) error {
+	var archived bool
+	query := fmt.Sprintf("SELECT tl.archived FROM %s tl INNER JOIN %s li ON li.list_id = tl.id WHERE li.item_id = $1 FOR SHARE OF tl, li",
+		todoListsTable, listsItemsTable)
+	if err := tx.GetContext(ctx, &archived, query, itemId); err != nil {
+		return err
+	}
+
+	if archived {
+		return model.ErrListArchived
+	}
+
+	return nil
+}
+
+// requireActiveItemWrite is requireItemWrite for writes to the parts of an
+// item, like its subtasks, labels and attachments, which are read-only in
+// archived lists as well.
+func requireActiveItemWrite(ctx context.Context, tx *sqlx.Tx, userId, itemId int) error {
+	if err := requireItemWrite(ctx, tx, userId, itemId); err != nil {
+		return err
+	}
+
+	return requireActiveItemTx(ctx, tx, itemId)
+}
+
+// checkAffected explains a write that matched no rows: sql.ErrNoRows when the
+// user can not see the entity at all, model.ErrForbidden when the role of the
+// user is too weak for the write.
+func checkAffected(res sql.Result, getRole func() (string, error)) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	if _, err = getRole(); err != nil {
+		return err
+	}
+
+	return model.ErrForbidden
+}
+
+func requireRole(role string, allowed ...string) error {
+	for _, r := range allowed {
+		if role == r {
+			return nil
+		}
+	}
+
+	return model.ErrForbidden
+}
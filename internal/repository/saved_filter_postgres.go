@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+type SavedFilterPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSavedFilterPostgres(db *sqlx.DB) *SavedFilterPostgres {
+	return &SavedFilterPostgres{db: db}
+}
+
+func (r *SavedFilterPostgres) Create(ctx context.Context, userId int, filter model.SavedFilter) (int, error) {
+	expression, err := json.Marshal(filter.Expression)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, name, expression) VALUES ($1, $2, $3) RETURNING id", savedFiltersTable)
+	if err = r.db.QueryRowContext(ctx, query, userId, filter.Name, expression).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, model.ErrFilterExists
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *SavedFilterPostgres) GetAll(ctx context.Context, userId int) ([]model.SavedFilter, error) {
+	var filters []model.SavedFilter
+	query := fmt.Sprintf("SELECT id, name, expression, created_at, updated_at FROM %s WHERE user_id = $1 ORDER BY name", savedFiltersTable)
+	err := r.db.SelectContext(ctx, &filters, query, userId)
+	return filters, err
+}
+
+func (r *SavedFilterPostgres) GetById(ctx context.Context, userId, filterId int) (model.SavedFilter, error) {
+	var filter model.SavedFilter
+	query := fmt.Sprintf("SELECT id, name, expression, created_at, updated_at FROM %s WHERE user_id = $1 AND id = $2", savedFiltersTable)
+	err := r.db.GetContext(ctx, &filter, query, userId, filterId)
+	return filter, err
+}
+
+func (r *SavedFilterPostgres) Update(ctx context.Context, userId, filterId int, input model.UpdateFilterInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name=$%d", argId))
+		args = append(args, *input.Name)
+		argId++
+	}
+
+	if input.Expression != nil {
+		expression, err := json.Marshal(input.Expression)
+		if err != nil {
+			return err
+		}
+		setValues = append(setValues, fmt.Sprintf("expression=$%d", argId))
+		args = append(args, expression)
+		argId++
+	}
+
+	setValues = append(setValues, "updated_at=NOW()")
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE user_id = $%d AND id = $%d", savedFiltersTable, strings.Join(setValues, ", "), argId, argId+1)
+	args = append(args, userId, filterId)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return model.ErrFilterExists
+		}
+		return err
+	}
+
+	return checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+}
+
+func (r *SavedFilterPostgres) Delete(ctx context.Context, userId, filterId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND id = $2", savedFiltersTable)
+	res, err := r.db.ExecContext(ctx, query, userId, filterId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+}
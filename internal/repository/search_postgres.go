@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultSearchConfig is the text search configuration the search_vector
+// columns are built with unless another one is configured.
+const DefaultSearchConfig = "english"
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+type SearchPostgres struct {
+	db     *sqlx.DB
+	config string
+}
+
+// NewSearchPostgres configures search with the given text search
+// configuration, DefaultSearchConfig if it is empty.
+func NewSearchPostgres(db *sqlx.DB, config string) *SearchPostgres {
+	if config == "" {
+		config = DefaultSearchConfig
+	}
+	return &SearchPostgres{db: db, config: config}
+}
+
+// Configure makes the configuration of the repository the one the
+// search_vector columns are built with and rebuilds them if it has changed.
+func (r *SearchPostgres) Configure(ctx context.Context) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET config = $1::regconfig WHERE config <> $1::regconfig", searchSettingsTable)
+	res, err := tx.ExecContext(ctx, query, r.config)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	changed, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if changed > 0 {
+		// setting the title fires the triggers that build the vectors
+		for _, table := range []string{todoListsTable, todoItemsTable} {
+			if _, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET title = title", table)); err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Search ranks the lists and items the user can access. Queries are parsed
+// with the configuration the vectors are built with, so stemming matches even
+// before Configure has run. Headlines are only built for the returned rows, as
+// they need the text of each row.
+func (r *SearchPostgres) Search(ctx context.Context, userId int, query string, limit int) ([]model.SearchResult, error) {
+	searchQuery := fmt.Sprintf(`WITH q AS (SELECT s.config, websearch_to_tsquery(s.config, $2) AS query FROM %[10]s s)
+		SELECT m.type, m.id, m.list_id,
+		       ts_headline(q.config, %[1]s, q.query, '%[3]s') AS title,
+		       ts_headline(q.config, %[2]s, q.query, '%[3]s') AS snippet,
+		       m.rank
+		FROM (SELECT '%[4]s' AS type, tl.id, tl.id AS list_id, tl.title, tl.description, ts_rank(tl.search_vector, q.query) AS rank
+		      FROM %[6]s tl INNER JOIN %[7]s ul ON ul.list_id = tl.id, q
+		      WHERE ul.user_id = $1 AND tl.deleted_at IS NULL AND tl.search_vector @@ q.query
+		      UNION ALL
+		      SELECT '%[5]s', ti.id, li.list_id, ti.title, ti.description, ts_rank(ti.search_vector, q.query)
+		      FROM %[8]s ti INNER JOIN %[9]s li ON li.item_id = ti.id INNER JOIN %[7]s ul ON ul.list_id = li.list_id, q
+		      WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND ti.search_vector @@ q.query
+		      ORDER BY rank DESC, type, id
+		      LIMIT $3) m, q
+		ORDER BY m.rank DESC, m.type, m.id`,
+		htmlEscaped("m.title"), htmlEscaped("m.description"), headlineOptions, model.SearchTypeList, model.SearchTypeItem,
+		todoListsTable, usersListsTable, todoItemsTable, listsItemsTable, searchSettingsTable)
+
+	var results []model.SearchResult
+	err := r.db.SelectContext(ctx, &results, searchQuery, userId, query, limit)
+
+	return results, err
+}
+
+// htmlEscaped escapes a text column like html.EscapeString before it is
+// highlighted, so the <mark> tags are the only markup in a headline. NULL
+// becomes the empty string.
+func htmlEscaped(column string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(replace(COALESCE(%s, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`,
+		column)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type SessionPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSessionPostgres(db *sqlx.DB) *SessionPostgres {
+	return &SessionPostgres{db: db}
+}
+
+func (r *SessionPostgres) Create(ctx context.Context, userId int, refreshTokenHash string, expiresAt time.Time) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, refresh_token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id", sessionsTable)
+	if err := r.db.QueryRowContext(ctx, query, userId, refreshTokenHash, expiresAt).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *SessionPostgres) GetById(ctx context.Context, sessionId int) (model.Session, error) {
+	var session model.Session
+	query := fmt.Sprintf("SELECT id, user_id, refresh_token_hash, expires_at, created_at, revoked_at FROM %s WHERE id = $1", sessionsTable)
+	err := r.db.GetContext(ctx, &session, query, sessionId)
+	return session, err
+}
+
+func (r *SessionPostgres) GetByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (model.Session, error) {
+	var session model.Session
+	query := fmt.Sprintf("SELECT id, user_id, refresh_token_hash, expires_at, created_at, revoked_at FROM %s WHERE refresh_token_hash = $1", sessionsTable)
+	err := r.db.GetContext(ctx, &session, query, refreshTokenHash)
+	return session, err
+}
+
+// Rotate replaces the refresh token of a session, but only if oldTokenHash is
+// still its current one. Otherwise sql.ErrNoRows is returned, so of two
+// refreshes with the same token only one succeeds. The old token is kept to
+// recognize it if it is used again.
+func (r *SessionPostgres) Rotate(ctx context.Context, sessionId int, oldTokenHash, refreshTokenHash string, expiresAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET refresh_token_hash = $1, expires_at = $2 WHERE id = $3 AND refresh_token_hash = $4 AND revoked_at IS NULL", sessionsTable)
+	res, err := tx.ExecContext(ctx, query, refreshTokenHash, expiresAt, sessionId, oldTokenHash)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	rotatedQuery := fmt.Sprintf("INSERT INTO %s (refresh_token_hash, session_id) VALUES ($1, $2)", rotatedRefreshTokensTable)
+	if _, err = tx.ExecContext(ctx, rotatedQuery, oldTokenHash, sessionId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SessionPostgres) Revoke(ctx context.Context, sessionId int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", sessionsTable)
+	_, err := r.db.ExecContext(ctx, query, sessionId)
+	return err
+}
+
+// RevokeRotated revokes the session a rotated refresh token belonged to. It
+// reports whether the token was one.
+func (r *SessionPostgres) RevokeRotated(ctx context.Context, refreshTokenHash string) (bool, error) {
+	var sessionId int
+	selectQuery := fmt.Sprintf("SELECT session_id FROM %s WHERE refresh_token_hash = $1", rotatedRefreshTokensTable)
+	err := r.db.GetContext(ctx, &sessionId, selectQuery, refreshTokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, r.Revoke(ctx, sessionId)
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"strings"
+)
+
+type SubtaskPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSubtaskPostgres(db *sqlx.DB) *SubtaskPostgres {
+	return &SubtaskPostgres{db: db}
+}
+
+func (r *SubtaskPostgres) Create(ctx context.Context, userId, itemId int, subtask model.Subtask) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (item_id, title, position)
+									SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM %s WHERE item_id = $1 RETURNING id`, subtasksTable, subtasksTable)
+	if err = tx.QueryRowContext(ctx, query, itemId, subtask.Title).Scan(&id); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *SubtaskPostgres) GetAll(ctx context.Context, userId, itemId int) ([]model.Subtask, error) {
+	if _, err := getItemRole(ctx, r.db, userId, itemId); err != nil {
+		return nil, err
+	}
+
+	var subtasks []model.Subtask
+	query := fmt.Sprintf("SELECT id, title, done, position, created_at FROM %s WHERE item_id = $1 ORDER BY position, id", subtasksTable)
+	err := r.db.SelectContext(ctx, &subtasks, query, itemId)
+	return subtasks, err
+}
+
+func (r *SubtaskPostgres) Update(ctx context.Context, userId, itemId, subtaskId int, input model.UpdateSubtaskInput) error {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if input.Title != nil {
+		setValues = append(setValues, fmt.Sprintf("title=$%d", argId))
+		args = append(args, *input.Title)
+		argId++
+	}
+
+	if input.Done != nil {
+		setValues = append(setValues, fmt.Sprintf("done=$%d", argId))
+		args = append(args, *input.Done)
+		argId++
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE item_id = $%d AND id = $%d", subtasksTable, strings.Join(setValues, ", "), argId, argId+1)
+	args = append(args, itemId, subtaskId)
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SubtaskPostgres) Delete(ctx context.Context, userId, itemId, subtaskId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE item_id = $1 AND id = $2", subtasksTable)
+	res, err := tx.ExecContext(ctx, query, itemId, subtaskId)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return "", sql.ErrNoRows })
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Reorder sets the positions of the subtasks to the order of ids, which must
+// list every subtask of the item.
+func (r *SubtaskPostgres) Reorder(ctx context.Context, userId, itemId int, ids []int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = requireActiveItemWrite(ctx, tx, userId, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	var current []int
+	selectQuery := fmt.Sprintf("SELECT id FROM %s WHERE item_id = $1 FOR UPDATE", subtasksTable)
+	if err = tx.SelectContext(ctx, &current, selectQuery, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if !sameIds(current, ids) {
+		_ = tx.Rollback()
+		return model.ErrSubtaskOrder
+	}
+
+	positions := make([]int64, len(ids))
+	subtaskIds := make([]int64, len(ids))
+	for i, id := range ids {
+		subtaskIds[i] = int64(id)
+		positions[i] = int64(i + 1)
+	}
+
+	updateQuery := fmt.Sprintf(`UPDATE %s st SET position = o.position FROM UNNEST($1::int[], $2::int[]) AS o(id, position)
+									WHERE st.id = o.id AND st.item_id = $3`, subtasksTable)
+	if _, err = tx.ExecContext(ctx, updateQuery, pq.Array(subtaskIds), pq.Array(positions), itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func sameIds(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	seen := make(map[int]bool, len(a))
+	for _, id := range a {
+		seen[id] = true
+	}
+	for _, id := range b {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+
+	return true
+}
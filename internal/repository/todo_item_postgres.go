@@ -2,11 +2,21 @@ package repository
 
 import (
 	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"strings"
+	"time"
 )
 
+var itemColumns = "ti.id, li.list_id, li.position, ti.title, ti.description, ti.done, ti.priority, ti.recurrence, ti.occurrence, ti.recurred, " +
+	"ti.start_at, ti.due_at, ti.completed_at, ti.created_at, ti.updated_at, " +
+	fmt.Sprintf(`(SELECT COUNT(*) FILTER (WHERE st.done) FROM %s st WHERE st.item_id = ti.id) AS "progress.done", `, subtasksTable) +
+	fmt.Sprintf(`(SELECT COUNT(*) FROM %s st WHERE st.item_id = ti.id) AS "progress.total"`, subtasksTable)
+
 type TodoItemRepository struct {
 	db *sqlx.DB
 }
@@ -15,22 +25,25 @@ func NewTodoItemRepository(db *sqlx.DB) *TodoItemRepository {
 	return &TodoItemRepository{db: db}
 }
 
-func (r *TodoItemRepository) Create(listId int, todoItem model.TodoItem) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoItemRepository) Create(ctx context.Context, userId, listId int, todoItem model.TodoItem) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
 
-	var itemId int
-	createItemsQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoItemsTable)
-	err = r.db.QueryRow(createItemsQuery, todoItem.Title, todoItem.Description).Scan(&itemId)
+	err = requireListWrite(ctx, tx, userId, listId)
+	if err == nil {
+		err = requireActiveListTx(ctx, tx, listId)
+	}
 	if err != nil {
 		_ = tx.Rollback()
 		return 0, err
 	}
 
-	createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
-	_, err = tx.Exec(createListItemsQuery, listId, itemId)
+	itemId, err := insertItem(ctx, tx, listId, todoItem)
+	if err == nil {
+		err = logActivity(ctx, tx, userId, model.EntityItem, itemId, listId, model.ActionCreated, nil)
+	}
 	if err != nil {
 		_ = tx.Rollback()
 		return 0, err
@@ -39,38 +52,405 @@ func (r *TodoItemRepository) Create(listId int, todoItem model.TodoItem) (int, e
 	return itemId, tx.Commit()
 }
 
-func (r *TodoItemRepository) GetAll(userId, listId int) ([]model.TodoItem, error) {
-	var items []model.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
-									INNER JOIN %s ul ON li.list_id = li.list_id WHERE li.list_id = $1 AND ul.user_id = $2`, todoItemsTable, listsItemsTable, usersListsTable)
+// CreateNextOccurrence adds the next occurrence of a recurring item to the same
+// list. The item is marked as recurred in the same transaction, so it has at
+// most one successor; 0 is returned if it already has one. The user who
+// completed the item is the actor.
+func (r *TodoItemRepository) CreateNextOccurrence(ctx context.Context, userId, itemId int, next model.TodoItem) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var listId int
+	markQuery := fmt.Sprintf("UPDATE %s ti SET recurred = TRUE FROM %s li WHERE li.item_id = ti.id AND ti.id = $1 AND NOT ti.recurred RETURNING li.list_id",
+		todoItemsTable, listsItemsTable)
+	err = tx.QueryRowContext(ctx, markQuery, itemId).Scan(&listId)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return 0, nil
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	nextId, err := insertItem(ctx, tx, listId, next)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	labelsQuery := fmt.Sprintf("INSERT INTO %s (item_id, label_id) SELECT $1, label_id FROM %s WHERE item_id = $2", itemsLabelsTable, itemsLabelsTable)
+	if _, err = tx.ExecContext(ctx, labelsQuery, nextId, itemId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err = logActivity(ctx, tx, userId, model.EntityItem, nextId, listId, model.ActionCreated, nil); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return nextId, tx.Commit()
+}
+
+func insertItem(ctx context.Context, tx *sqlx.Tx, listId int, todoItem model.TodoItem) (int, error) {
+	var itemId int
+	createItemsQuery := fmt.Sprintf("INSERT INTO %s (title, description, priority, recurrence, occurrence, start_at, due_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id", todoItemsTable)
+	err := tx.QueryRowContext(ctx, createItemsQuery, todoItem.Title, todoItem.Description, todoItem.Priority, todoItem.Recurrence, todoItem.Occurrence, todoItem.StartAt, todoItem.DueAt).Scan(&itemId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = insertListItem(ctx, tx, listId, itemId); err != nil {
+		return 0, err
+	}
+
+	return itemId, nil
+}
+
+// insertListItem puts the item at the end of the list.
+func insertListItem(ctx context.Context, tx *sqlx.Tx, listId, itemId int) error {
+	query := fmt.Sprintf("INSERT INTO %s (list_id, item_id, position) VALUES ($1, $2, %s)", listsItemsTable, itemPositions.last("$1"))
+	_, err := tx.ExecContext(ctx, query, listId, itemId)
+	return err
+}
+
+// Move puts the item at the end of another list. The user must be allowed to
+// change the items of both lists and neither may be archived.
+func (r *TodoItemRepository) Move(ctx context.Context, userId, itemId, listId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = requireItemWrite(ctx, tx, userId, itemId)
+	if err == nil {
+		err = requireListWrite(ctx, tx, userId, listId)
+	}
+	if err == nil {
+		err = requireActiveItemTx(ctx, tx, itemId)
+	}
+	if err == nil {
+		err = requireActiveListTx(ctx, tx, listId)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	oldListId, err := itemListId(ctx, tx, itemId)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET list_id = $1, position = %s WHERE item_id = $2", listsItemsTable, itemPositions.last("$1"))
+	if _, err = tx.ExecContext(ctx, query, listId, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET updated_at = NOW() WHERE id = $1", todoItemsTable), itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	changes := model.Changes{"list_id": {Old: oldListId, New: listId}}
+	if err = logActivity(ctx, tx, userId, model.EntityItem, itemId, listId, model.ActionMoved, changes); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Copy adds a copy of the item with its labels and subtasks to the end of a
+// list. The user must see the item and be allowed to change the items of the
+// list, which must not be archived. A copied recurring item starts a new
+// series.
+func (r *TodoItemRepository) Copy(ctx context.Context, userId, itemId, listId int) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
 
-	if err := r.db.Select(&items, query, listId, userId); err != nil {
-		return nil, err
+	_, err = getItemRole(ctx, tx, userId, itemId)
+	if err == nil {
+		err = requireListWrite(ctx, tx, userId, listId)
+	}
+	if err == nil {
+		err = requireActiveListTx(ctx, tx, listId)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	var copyId int
+	itemQuery := fmt.Sprintf(`INSERT INTO %[1]s (title, description, done, priority, recurrence, start_at, due_at, completed_at)
+									SELECT title, description, done, priority, recurrence, start_at, due_at, completed_at FROM %[1]s WHERE id = $1 RETURNING id`, todoItemsTable)
+	if err = tx.QueryRowContext(ctx, itemQuery, itemId).Scan(&copyId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
 	}
 
-	return items, nil
+	if err = insertListItem(ctx, tx, listId, copyId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	labelsQuery := fmt.Sprintf("INSERT INTO %s (item_id, label_id) SELECT $1, label_id FROM %s WHERE item_id = $2", itemsLabelsTable, itemsLabelsTable)
+	if _, err = tx.ExecContext(ctx, labelsQuery, copyId, itemId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	subtasksQuery := fmt.Sprintf("INSERT INTO %s (item_id, title, done, position) SELECT $1, title, done, position FROM %s WHERE item_id = $2", subtasksTable, subtasksTable)
+	if _, err = tx.ExecContext(ctx, subtasksQuery, copyId, itemId); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err = logActivity(ctx, tx, userId, model.EntityItem, copyId, listId, model.ActionCreated, nil); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	return copyId, tx.Commit()
+}
+
+// GetAll returns a page of the items of a list, by default in their manual
+// order, and the cursor of the next page.
+func (r *TodoItemRepository) GetAll(ctx context.Context, userId, listId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error) {
+	return r.selectItems(ctx, userId, []string{"ul.user_id = $1", "li.list_id = $2"}, []interface{}{userId, listId}, filter, page, "position")
+}
+
+// GetAllByUser returns a page of the items of all lists of the user, by default
+// the ones due first first, and the cursor of the next page.
+func (r *TodoItemRepository) GetAllByUser(ctx context.Context, userId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error) {
+	return r.selectItems(ctx, userId, []string{"ul.user_id = $1"}, []interface{}{userId}, filter, page, "due_at")
 }
 
-func (r *TodoItemRepository) GetById(userId, itemId int) (model.TodoItem, error) {
-	query := fmt.Sprintf("SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s il ON il.item_id = ti.id INNER JOIN %s ul ON ul.list_id = il.list_id WHERE ul.user_id = $1 AND ti.id = $2", todoItemsTable, listsItemsTable, usersListsTable)
+// GetAllByFilter returns a page of the items of all lists of the user that
+// match the filter expression, by default the ones due first first, and the
+// cursor of the next page.
+func (r *TodoItemRepository) GetAllByFilter(ctx context.Context, userId int, expr model.FilterExpr, page model.PageQuery) ([]model.TodoItem, string, error) {
+	condition, args, err := compileFilter(expr, userId, 2, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+
+	return r.selectItems(ctx, userId, []string{"ul.user_id = $1", condition}, append([]interface{}{userId}, args...), model.ItemFilter{}, page, "due_at")
+}
+
+// selectItems returns a page of the items visible to a user that match both
+// the given conditions and the filter. The conditions use the ti, li and ul
+// aliases and the first len(args) placeholders.
+func (r *TodoItemRepository) selectItems(ctx context.Context, userId int, conditions []string, args []interface{}, filter model.ItemFilter, page model.PageQuery, defaultSort string) ([]model.TodoItem, string, error) {
+	keys, err := newKeyset(itemSorts, page, defaultSort, "ti.id")
+	if err != nil {
+		return nil, "", err
+	}
+
+	argId := len(args) + 1
+
+	conditions = append(conditions, "ti.deleted_at IS NULL")
+
+	if filter.DueBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("ti.due_at < $%d", argId))
+		args = append(args, *filter.DueBefore)
+		argId++
+	}
+
+	if filter.Overdue {
+		conditions = append(conditions, "ti.due_at < NOW() AND NOT ti.done")
+	}
+
+	if filter.Priority != nil {
+		conditions = append(conditions, fmt.Sprintf("ti.priority = $%d", argId))
+		args = append(args, *filter.Priority)
+		argId++
+	}
+
+	if filter.LabelId != nil {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM %s il INNER JOIN %s l ON l.id = il.label_id AND l.user_id = $%d WHERE il.item_id = ti.id AND il.label_id = $%d)",
+			itemsLabelsTable, labelsTable, argId, argId+1))
+		args = append(args, userId, *filter.LabelId)
+		argId += 2
+	}
+
+	if filter.Done != nil {
+		conditions = append(conditions, fmt.Sprintf("ti.done = $%d", argId))
+		args = append(args, *filter.Done)
+		argId++
+	}
+
+	if filter.Query != nil {
+		conditions = append(conditions, fmt.Sprintf("(ti.title ILIKE $%d OR ti.description ILIKE $%d)", argId, argId))
+		args = append(args, containsPattern(*filter.Query))
+		argId++
+	}
+
+	if condition, keyArgs := keys.condition(argId); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, keyArgs...)
+		argId += len(keyArgs)
+	}
+
+	var rows []struct {
+		model.TodoItem
+		SortKey string `db:"sort_key"`
+	}
+	query := fmt.Sprintf(`SELECT %s, %s FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+									INNER JOIN %s ul ON ul.list_id = li.list_id WHERE %s ORDER BY %s LIMIT $%d`,
+		itemColumns, keys.column(), todoItemsTable, listsItemsTable, usersListsTable, strings.Join(conditions, " AND "), keys.orderBy(), argId)
+	args = append(args, keys.fetch())
+
+	if err = r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(rows) > 0 {
+		last := min(len(rows), keys.limit) - 1
+		next = keys.next(len(rows), rows[last].SortKey, rows[last].Id)
+		rows = rows[:last+1]
+	}
+
+	items := make([]model.TodoItem, len(rows))
+	for i := range rows {
+		items[i] = rows[i].TodoItem
+	}
+
+	if err = r.loadLabels(ctx, userId, items); err != nil {
+		return nil, "", err
+	}
+
+	return items, next, nil
+}
+
+// loadLabels fills in the labels of the items with a single query. Labels
+// belong to a user, so members of a shared list only see their own ones.
+func (r *TodoItemRepository) loadLabels(ctx context.Context, userId int, items []model.TodoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(items))
+	byId := make(map[int]*model.TodoItem, len(items))
+	for i := range items {
+		ids[i] = int64(items[i].Id)
+		items[i].Labels = make([]model.Label, 0)
+		byId[items[i].Id] = &items[i]
+	}
+
+	var labels []model.ItemLabel
+	query := fmt.Sprintf("SELECT il.item_id, l.id, l.name, l.color, l.created_at FROM %s il INNER JOIN %s l ON l.id = il.label_id WHERE il.item_id = ANY($1) AND l.user_id = $2 ORDER BY l.name",
+		itemsLabelsTable, labelsTable)
+	if err := r.db.SelectContext(ctx, &labels, query, pq.Array(ids), userId); err != nil {
+		return err
+	}
+
+	for _, label := range labels {
+		item := byId[label.ItemId]
+		item.Labels = append(item.Labels, label.Label)
+	}
+
+	return nil
+}
+
+// Reorder moves an item within its list.
+func (r *TodoItemRepository) Reorder(ctx context.Context, userId, listId int, input model.ReorderInput) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = requireListWrite(ctx, tx, userId, listId)
+	if err == nil {
+		err = requireActiveListTx(ctx, tx, listId)
+	}
+	if err == nil {
+		err = itemPositions.move(ctx, tx, listId, input)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TodoItemRepository) GetById(ctx context.Context, userId, itemId int) (model.TodoItem, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s ti INNER JOIN %s li ON li.item_id = ti.id INNER JOIN %s ul ON ul.list_id = li.list_id WHERE ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NULL", itemColumns, todoItemsTable, listsItemsTable, usersListsTable)
 	var item model.TodoItem
-	if err := r.db.Get(&item, query, userId, itemId); err != nil {
+	if err := r.db.GetContext(ctx, &item, query, userId, itemId); err != nil {
+		return item, err
+	}
+
+	items := []model.TodoItem{item}
+	if err := r.loadLabels(ctx, userId, items); err != nil {
+		return item, err
+	}
+	item = items[0]
+
+	item.Subtasks = make([]model.Subtask, 0)
+	subtasksQuery := fmt.Sprintf("SELECT id, title, done, position, created_at FROM %s WHERE item_id = $1 ORDER BY position, id", subtasksTable)
+	if err := r.db.SelectContext(ctx, &item.Subtasks, subtasksQuery, itemId); err != nil {
 		return item, err
 	}
 
 	return item, nil
 }
 
-func (r *TodoItemRepository) Delete(userId, itemId int) error {
-	query := fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s ul 
-       								WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2`,
-		todoItemsTable, listsItemsTable, usersListsTable)
+// GetListId returns the list of an item the user can see.
+func (r *TodoItemRepository) GetListId(ctx context.Context, userId, itemId int) (int, error) {
+	var listId int
+	query := fmt.Sprintf(`SELECT li.list_id FROM %s li INNER JOIN %s ul ON ul.list_id = li.list_id INNER JOIN %s ti ON ti.id = li.item_id
+									WHERE ul.user_id = $1 AND li.item_id = $2 AND ti.deleted_at IS NULL`,
+		listsItemsTable, usersListsTable, todoItemsTable)
+	err := r.db.GetContext(ctx, &listId, query, userId, itemId)
+	return listId, err
+}
 
-	_, err := r.db.Exec(query, userId, itemId)
-	return err
+// Delete moves the item to the trash.
+func (r *TodoItemRepository) Delete(ctx context.Context, userId, itemId int) error {
+	query := fmt.Sprintf(`UPDATE %s ti SET deleted_at = NOW() FROM %s li, %s ul
+       								WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2 AND ul.role IN %s AND ti.deleted_at IS NULL`,
+		todoItemsTable, listsItemsTable, usersListsTable, writeRoles)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, query, userId, itemId)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return getItemRole(ctx, tx, userId, itemId) })
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	listId, err := itemListId(ctx, tx, itemId)
+	if err == nil {
+		err = requireActiveListTx(ctx, tx, listId)
+	}
+	if err == nil {
+		err = logActivity(ctx, tx, userId, model.EntityItem, itemId, listId, model.ActionDeleted, nil)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TodoItemRepository) Update(userId, itemId int, updateItemInput model.UpdateItemInput) error {
+func (r *TodoItemRepository) Update(ctx context.Context, userId, itemId int, updateItemInput model.UpdateItemInput) error {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argId := 1
@@ -89,14 +469,73 @@ func (r *TodoItemRepository) Update(userId, itemId int, updateItemInput model.Up
 
 	if updateItemInput.Done != nil {
 		setValues = append(setValues, fmt.Sprintf("done=$%d", argId))
+		// completed_at keeps the first completion time until the item is reopened
+		setValues = append(setValues, fmt.Sprintf("completed_at=CASE WHEN $%d THEN COALESCE(ti.completed_at, NOW()) END", argId))
 		args = append(args, *updateItemInput.Done)
 		argId++
 	}
 
+	if updateItemInput.Priority != nil {
+		setValues = append(setValues, fmt.Sprintf("priority=$%d", argId))
+		args = append(args, *updateItemInput.Priority)
+		argId++
+	}
+
+	if updateItemInput.StartAt.Set {
+		setValues = append(setValues, fmt.Sprintf("start_at=$%d", argId))
+		args = append(args, updateItemInput.StartAt.Time)
+		argId++
+	}
+
+	if updateItemInput.DueAt.Set {
+		setValues = append(setValues, fmt.Sprintf("due_at=$%d", argId))
+		args = append(args, updateItemInput.DueAt.Time)
+		argId++
+	}
+
+	if updateItemInput.Recurrence.Set {
+		setValues = append(setValues, fmt.Sprintf("recurrence=$%d", argId))
+		args = append(args, updateItemInput.Recurrence.String)
+		argId++
+	}
+
+	setValues = append(setValues, "updated_at=NOW()")
+
 	setValuesQuery := strings.Join(setValues, ", ")
-	query := fmt.Sprintf("UPDATE %s ti SET %s FROM %s il, %s ul WHERE il.item_id = ti.id AND il.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d", todoItemsTable, setValuesQuery, listsItemsTable, usersListsTable, argId, argId+1)
+	query := fmt.Sprintf("UPDATE %s ti SET %s FROM %s il, %s ul WHERE il.item_id = ti.id AND il.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d AND ul.role IN %s AND ti.deleted_at IS NULL", todoItemsTable, setValuesQuery, listsItemsTable, usersListsTable, argId, argId+1, writeRoles)
 	args = append(args, userId, itemId)
 
-	_, err := r.db.Exec(query, args...)
-	return err
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// the old values are locked, so the logged changes are the ones made
+	var old model.TodoItem
+	oldQuery := fmt.Sprintf(`SELECT li.list_id, ti.title, ti.description, ti.done, ti.priority, ti.start_at, ti.due_at, ti.recurrence
+									FROM %s ti INNER JOIN %s li ON li.item_id = ti.id WHERE ti.id = $1 AND ti.deleted_at IS NULL FOR UPDATE OF ti`,
+		todoItemsTable, listsItemsTable)
+	if err = tx.GetContext(ctx, &old, oldQuery, itemId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return getItemRole(ctx, tx, userId, itemId) })
+	}
+	if err == nil {
+		err = requireActiveListTx(ctx, tx, old.ListId)
+	}
+	if err == nil {
+		if changes := updateItemInput.Changes(old); len(changes) > 0 {
+			err = logActivity(ctx, tx, userId, model.EntityItem, itemId, old.ListId, model.ActionUpdated, changes)
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"TodoApp/internal/model"
+	"context"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
@@ -15,55 +16,162 @@ type TodoListPostgres struct {
 func NewTodoListPostgres(db *sqlx.DB) *TodoListPostgres {
 	return &TodoListPostgres{db: db}
 }
-func (r *TodoListPostgres) Create(userId int, list model.TodoList) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoListPostgres) Create(ctx context.Context, userId int, list model.TodoList) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
 
 	var id int
 	createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES($1, $2) RETURNING ID", todoListsTable)
-	row := tx.QueryRow(createListQuery, list.Title, list.Description)
+	row := tx.QueryRowContext(ctx, createListQuery, list.Title, list.Description)
 	if err = row.Scan(&id); err != nil {
 		_ = tx.Rollback()
 		return 0, err
 	}
 
-	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES($1, $2)", usersListsTable)
-	_, err = tx.Exec(createUsersListQuery, userId, id)
+	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id, role, position) VALUES($1, $2, $3, %s)", usersListsTable, listPositions.last("$1"))
+	_, err = tx.ExecContext(ctx, createUsersListQuery, userId, id, model.RoleOwner)
 	if err != nil {
 		_ = tx.Rollback()
 		return 0, err
 	}
 
+	if err = logActivity(ctx, tx, userId, model.EntityList, id, id, model.ActionCreated, nil); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
 	return id, tx.Commit()
 }
 
-func (r *TodoListPostgres) GetAll(userId int) ([]model.TodoList, error) {
-	var lists []model.TodoList
+// GetAll returns a page of the lists of the user, by default in the order the
+// user has set, and the cursor of the next page.
+func (r *TodoListPostgres) GetAll(ctx context.Context, userId int, filter model.ListFilter, page model.PageQuery) ([]model.TodoList, string, error) {
+	keys, err := newKeyset(listSorts, page, "position", "tl.id")
+	if err != nil {
+		return nil, "", err
+	}
+
+	conditions := []string{"ul.user_id = $1", "tl.deleted_at IS NULL"}
+	args := []interface{}{userId}
+	argId := 2
+
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "NOT tl.archived")
+	}
+
+	if filter.Query != nil {
+		conditions = append(conditions, fmt.Sprintf("(tl.title ILIKE $%d OR tl.description ILIKE $%d)", argId, argId))
+		args = append(args, containsPattern(*filter.Query))
+		argId++
+	}
+
+	if condition, keyArgs := keys.condition(argId); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, keyArgs...)
+		argId += len(keyArgs)
+	}
+
+	var rows []struct {
+		model.TodoList
+		SortKey string `db:"sort_key"`
+	}
+	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description, ul.role, ul.position, tl.archived, tl.created_at, %s FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE %s ORDER BY %s LIMIT $%d",
+		keys.column(), todoListsTable, usersListsTable, strings.Join(conditions, " AND "), keys.orderBy(), argId)
+	args = append(args, keys.fetch())
+
+	if err = r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(rows) > 0 {
+		last := min(len(rows), keys.limit) - 1
+		next = keys.next(len(rows), rows[last].SortKey, rows[last].Id)
+		rows = rows[:last+1]
+	}
 
-	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id = $1", todoListsTable, usersListsTable)
-	err := r.db.Select(&lists, query, userId)
+	lists := make([]model.TodoList, len(rows))
+	for i := range rows {
+		lists[i] = rows[i].TodoList
+	}
 
-	return lists, err
+	return lists, next, nil
 }
 
-func (r *TodoListPostgres) GetById(userId, listId int) (model.TodoList, error) {
+func (r *TodoListPostgres) GetById(ctx context.Context, userId, listId int) (model.TodoList, error) {
 	var list model.TodoList
 
-	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2", todoListsTable, usersListsTable)
-	err := r.db.Get(&list, query, userId, listId)
+	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description, ul.role, ul.position, tl.archived, tl.created_at FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL", todoListsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &list, query, userId, listId)
 
 	return list, err
 }
 
-func (r *TodoListPostgres) Delete(userId, listId int) error {
-	query := fmt.Sprintf("DELETE FROM %s tl USING %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2", todoListsTable, usersListsTable)
-	_, err := r.db.Exec(query, userId, listId)
-	return err
+// Reorder moves a list within the lists of the user. Every member orders the
+// shared lists on their own.
+func (r *TodoListPostgres) Reorder(ctx context.Context, userId int, input model.ReorderInput) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = listPositions.move(ctx, tx, userId, input); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete moves the list to the trash with the items it has. Items already in
+// the trash stay there when the list is restored.
+func (r *TodoListPostgres) Delete(ctx context.Context, userId, listId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s tl SET deleted_at = NOW() FROM %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2 AND ul.role = '%s' AND tl.deleted_at IS NULL",
+		todoListsTable, usersListsTable, model.RoleOwner)
+	res, err := tx.ExecContext(ctx, query, userId, listId)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return getListRole(ctx, tx, userId, listId) })
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	itemsQuery := fmt.Sprintf("UPDATE %s ti SET deleted_at = NOW(), deleted_with_list = TRUE FROM %s li WHERE li.item_id = ti.id AND li.list_id = $1 AND ti.deleted_at IS NULL",
+		todoItemsTable, listsItemsTable)
+	if _, err = tx.ExecContext(ctx, itemsQuery, listId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = logActivity(ctx, tx, userId, model.EntityList, listId, listId, model.ActionDeleted, nil); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetArchived archives or unarchives the list. Only owners may do it.
+func (r *TodoListPostgres) SetArchived(ctx context.Context, userId, listId int, archived bool) error {
+	query := fmt.Sprintf("UPDATE %s tl SET archived = $1 FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id = $2 AND ul.user_id = $3 AND ul.role = '%s' AND tl.deleted_at IS NULL",
+		todoListsTable, usersListsTable, model.RoleOwner)
+	res, err := r.db.ExecContext(ctx, query, archived, listId, userId)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res, func() (string, error) { return getListRole(ctx, r.db, userId, listId) })
 }
 
-func (r *TodoListPostgres) Update(userId, listId int, updateRequest model.UpdateListInput) error {
+func (r *TodoListPostgres) Update(ctx context.Context, userId, listId int, updateRequest model.UpdateListInput) error {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argId := 1
@@ -81,12 +189,38 @@ func (r *TodoListPostgres) Update(userId, listId int, updateRequest model.Update
 	}
 
 	setQuery := strings.Join(setValues, ", ")
-	query := fmt.Sprintf("UPDATE %s tl SET %s FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id=$%d AND ul.user_id = $%d", todoListsTable, setQuery, usersListsTable, argId, argId+1)
+	query := fmt.Sprintf("UPDATE %s tl SET %s FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id=$%d AND ul.user_id = $%d AND ul.role = '%s' AND tl.deleted_at IS NULL", todoListsTable, setQuery, usersListsTable, argId, argId+1, model.RoleOwner)
 
 	args = append(args, listId, userId)
 	logrus.Debugf("update query: %s", query)
 	logrus.Debugf("update args: %v", args)
 
-	_, err := r.db.Exec(query, args...)
-	return err
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// the old values are locked, so the logged changes are the ones made
+	var old model.TodoList
+	oldQuery := fmt.Sprintf("SELECT title, description FROM %s WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", todoListsTable)
+	if err = tx.GetContext(ctx, &old, oldQuery, listId); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err == nil {
+		err = checkAffected(res, func() (string, error) { return getListRole(ctx, tx, userId, listId) })
+	}
+	if err == nil {
+		if changes := updateRequest.Changes(old); len(changes) > 0 {
+			err = logActivity(ctx, tx, userId, model.EntityList, listId, listId, model.ActionUpdated, changes)
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
 }
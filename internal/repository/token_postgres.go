@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+)
+
+const tokenColumns = "id, user_id, name, scope, token_hash, created_at, expires_at, last_used_at, revoked_at"
+
+type TokenPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTokenPostgres(db *sqlx.DB) *TokenPostgres {
+	return &TokenPostgres{db: db}
+}
+
+func (r *TokenPostgres) Create(ctx context.Context, token model.PersonalAccessToken) (model.PersonalAccessToken, error) {
+	var created model.PersonalAccessToken
+	query := fmt.Sprintf("INSERT INTO %s (user_id, name, scope, token_hash, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING %s",
+		personalAccessTokensTable, tokenColumns)
+	err := r.db.GetContext(ctx, &created, query, token.UserId, token.Name, token.Scope, token.TokenHash, token.ExpiresAt)
+	return created, err
+}
+
+func (r *TokenPostgres) GetAll(ctx context.Context, userId int) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 AND revoked_at IS NULL ORDER BY id", tokenColumns, personalAccessTokensTable)
+	err := r.db.SelectContext(ctx, &tokens, query, userId)
+	return tokens, err
+}
+
+func (r *TokenPostgres) Revoke(ctx context.Context, userId, tokenId int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = NOW() WHERE user_id = $1 AND id = $2 AND revoked_at IS NULL", personalAccessTokensTable)
+	res, err := r.db.ExecContext(ctx, query, userId, tokenId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Use looks up an active token by its hash and records that it was used.
+func (r *TokenPostgres) Use(ctx context.Context, tokenHash string) (model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	query := fmt.Sprintf(`UPDATE %s SET last_used_at = NOW()
+									WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+									RETURNING %s`, personalAccessTokensTable, tokenColumns)
+	err := r.db.GetContext(ctx, &token, query, tokenHash)
+	return token, err
+}
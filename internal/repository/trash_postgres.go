@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"TodoApp/internal/model"
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type TrashPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTrashPostgres(db *sqlx.DB) *TrashPostgres {
+	return &TrashPostgres{db: db}
+}
+
+// GetAll returns the lists the user owns and the items the user may change
+// that are in the trash, the last deleted first.
+func (r *TrashPostgres) GetAll(ctx context.Context, userId int) ([]model.TrashEntry, error) {
+	query := fmt.Sprintf(`SELECT '%[1]s' AS type, tl.id, tl.id AS list_id, COALESCE(tl.title, '') AS title, tl.deleted_at
+									FROM %[3]s tl INNER JOIN %[4]s ul ON ul.list_id = tl.id
+									WHERE ul.user_id = $1 AND ul.role = '%[7]s' AND tl.deleted_at IS NOT NULL
+									UNION ALL
+									SELECT '%[2]s', ti.id, li.list_id, ti.title, ti.deleted_at
+									FROM %[5]s ti INNER JOIN %[6]s li ON li.item_id = ti.id INNER JOIN %[4]s ul ON ul.list_id = li.list_id
+									WHERE ul.user_id = $1 AND ul.role IN %[8]s AND ti.deleted_at IS NOT NULL AND NOT ti.deleted_with_list
+									ORDER BY deleted_at DESC, type, id`,
+		model.TrashTypeList, model.TrashTypeItem, todoListsTable, usersListsTable, todoItemsTable, listsItemsTable, model.RoleOwner, writeRoles)
+
+	var entries []model.TrashEntry
+	err := r.db.SelectContext(ctx, &entries, query, userId)
+	return entries, err
+}
+
+// Restore takes a list with the items deleted with it, or a single item, out
+// of the trash. An item can not be restored while its list is in the trash.
+func (r *TrashPostgres) Restore(ctx context.Context, userId int, entryType string, id int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if entryType == model.TrashTypeList {
+		err = restoreList(ctx, tx, userId, id)
+	} else {
+		err = restoreItem(ctx, tx, userId, id)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func restoreList(ctx context.Context, tx *sqlx.Tx, userId, listId int) error {
+	if err := requireTrashedListOwner(ctx, tx, userId, listId); err != nil {
+		return err
+	}
+
+	listQuery := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1", todoListsTable)
+	if _, err := tx.ExecContext(ctx, listQuery, listId); err != nil {
+		return err
+	}
+
+	itemsQuery := fmt.Sprintf("UPDATE %s ti SET deleted_at = NULL, deleted_with_list = FALSE FROM %s li WHERE li.item_id = ti.id AND li.list_id = $1 AND ti.deleted_with_list",
+		todoItemsTable, listsItemsTable)
+	if _, err := tx.ExecContext(ctx, itemsQuery, listId); err != nil {
+		return err
+	}
+
+	return logActivity(ctx, tx, userId, model.EntityList, listId, listId, model.ActionRestored, nil)
+}
+
+func restoreItem(ctx context.Context, tx *sqlx.Tx, userId, itemId int) error {
+	listDeleted, err := requireTrashedItemWrite(ctx, tx, userId, itemId)
+	if err != nil {
+		return err
+	}
+	if listDeleted {
+		return model.ErrListInTrash
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1", todoItemsTable)
+	if _, err = tx.ExecContext(ctx, query, itemId); err != nil {
+		return err
+	}
+
+	listId, err := itemListId(ctx, tx, itemId)
+	if err != nil {
+		return err
+	}
+
+	return logActivity(ctx, tx, userId, model.EntityItem, itemId, listId, model.ActionRestored, nil)
+}
+
+// Purge deletes a list with all its items, or a single item, from the trash
+// for good. It returns the keys of the blobs of the deleted attachments, which
+// the caller deletes once the transaction is committed.
+func (r *TrashPostgres) Purge(ctx context.Context, userId int, entryType string, id int) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var blobKeys []string
+	if entryType == model.TrashTypeList {
+		err = requireTrashedListOwner(ctx, tx, userId, id)
+		if err == nil {
+			_, blobKeys, err = purgeLists(ctx, tx, "tl.id = $1", id)
+		}
+	} else {
+		_, err = requireTrashedItemWrite(ctx, tx, userId, id)
+		if err == nil {
+			_, blobKeys, err = purgeItems(ctx, tx, "ti.id = $1 AND ti.deleted_at IS NOT NULL", id)
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return blobKeys, tx.Commit()
+}
+
+// PurgeExpired deletes everything that went to the trash before the given time.
+// It returns the number of deleted lists and items and the keys of the blobs of
+// their attachments.
+func (r *TrashPostgres) PurgeExpired(ctx context.Context, before time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lists, listBlobKeys, err := purgeLists(ctx, tx, "tl.deleted_at < $1", before)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, nil, err
+	}
+
+	items, itemBlobKeys, err := purgeItems(ctx, tx, "ti.deleted_at < $1 AND NOT ti.deleted_with_list", before)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, nil, err
+	}
+
+	return lists + items, append(listBlobKeys, itemBlobKeys...), tx.Commit()
+}
+
+// purgeLists deletes the lists in the trash that match the condition on the tl
+// alias and their items, which the lists_items cascade would leave behind. It
+// returns the number of deleted lists and items and the blob keys of their
+// attachments.
+func purgeLists(ctx context.Context, tx *sqlx.Tx, condition string, arg interface{}) (int64, []string, error) {
+	itemsCondition := fmt.Sprintf("ti.id IN (SELECT li.item_id FROM %s li INNER JOIN %s tl ON tl.id = li.list_id WHERE tl.deleted_at IS NOT NULL AND %s)",
+		listsItemsTable, todoListsTable, condition)
+	items, blobKeys, err := purgeItems(ctx, tx, itemsCondition, arg)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	listsQuery := fmt.Sprintf("DELETE FROM %s tl WHERE tl.deleted_at IS NOT NULL AND %s", todoListsTable, condition)
+	res, err := tx.ExecContext(ctx, listsQuery, arg)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lists, err := res.RowsAffected()
+	return lists + items, blobKeys, err
+}
+
+// purgeItems deletes the items that match the condition on the ti alias. The
+// attachments are deleted first, as the cascade would lose the keys of their
+// blobs. It returns the number of deleted items and the blob keys.
+func purgeItems(ctx context.Context, tx *sqlx.Tx, condition string, arg interface{}) (int64, []string, error) {
+	var blobKeys []string
+	attachmentsQuery := fmt.Sprintf("DELETE FROM %s a USING %s ti WHERE a.item_id = ti.id AND %s RETURNING a.blob_key",
+		attachmentsTable, todoItemsTable, condition)
+	if err := tx.SelectContext(ctx, &blobKeys, attachmentsQuery, arg); err != nil {
+		return 0, nil, err
+	}
+
+	itemsQuery := fmt.Sprintf("DELETE FROM %s ti WHERE %s", todoItemsTable, condition)
+	res, err := tx.ExecContext(ctx, itemsQuery, arg)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	items, err := res.RowsAffected()
+	return items, blobKeys, err
+}
+
+// requireTrashedListOwner returns sql.ErrNoRows unless the list is in the
+// trash, and model.ErrForbidden unless the user owns it.
+func requireTrashedListOwner(ctx context.Context, q sqlx.QueryerContext, userId, listId int) error {
+	var role string
+	query := fmt.Sprintf("SELECT ul.role FROM %s ul INNER JOIN %s tl ON tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NOT NULL FOR UPDATE OF tl",
+		usersListsTable, todoListsTable)
+	if err := sqlx.GetContext(ctx, q, &role, query, userId, listId); err != nil {
+		return err
+	}
+
+	return requireRole(role, model.RoleOwner)
+}
+
+// requireTrashedItemWrite returns sql.ErrNoRows unless the item is in the
+// trash, and model.ErrForbidden unless the user may change the items of its
+// list. It also tells whether the list is in the trash.
+func requireTrashedItemWrite(ctx context.Context, q sqlx.QueryerContext, userId, itemId int) (bool, error) {
+	var entry struct {
+		Role        string `db:"role"`
+		ListDeleted bool   `db:"list_deleted"`
+	}
+	query := fmt.Sprintf(`SELECT ul.role, tl.deleted_at IS NOT NULL AS list_deleted
+									FROM %s ti INNER JOIN %s li ON li.item_id = ti.id INNER JOIN %s tl ON tl.id = li.list_id INNER JOIN %s ul ON ul.list_id = li.list_id
+									WHERE ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NOT NULL FOR UPDATE OF ti`,
+		todoItemsTable, listsItemsTable, todoListsTable, usersListsTable)
+	if err := sqlx.GetContext(ctx, q, &entry, query, userId, itemId); err != nil {
+		return false, err
+	}
+
+	return entry.ListDeleted, requireRole(entry.Role, model.RoleOwner, model.RoleEditor)
+}
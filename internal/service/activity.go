@@ -0,0 +1,39 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type ActivityService struct {
+	repo repository.Activity
+}
+
+func NewActivityService(repo repository.Activity) *ActivityService {
+	return &ActivityService{repo: repo}
+}
+
+func (s *ActivityService) GetByList(ctx context.Context, userId, listId int, page model.PageQuery) ([]model.Activity, string, error) {
+	if err := page.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	activities, next, err := s.repo.GetByList(ctx, userId, listId, page)
+	if activities == nil {
+		activities = make([]model.Activity, 0)
+	}
+	return activities, next, err
+}
+
+func (s *ActivityService) GetByItem(ctx context.Context, userId, itemId int, page model.PageQuery) ([]model.Activity, string, error) {
+	if err := page.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	activities, next, err := s.repo.GetByItem(ctx, userId, itemId, page)
+	if activities == nil {
+		activities = make([]model.Activity, 0)
+	}
+	return activities, next, err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"TodoApp/internal/storage"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+)
+
+const blobKeyBytes = 16
+
+type AttachmentService struct {
+	repo    repository.Attachment
+	blobs   storage.BlobStore
+	maxSize int64
+}
+
+func NewAttachmentService(repo repository.Attachment, blobs storage.BlobStore, maxSize int64) *AttachmentService {
+	return &AttachmentService{repo: repo, blobs: blobs, maxSize: maxSize}
+}
+
+// Upload stores the contents of r under a new key and then its metadata. Write
+// access is checked before anything is stored. The blob is deleted again if it
+// turns out too large or the metadata can not be stored.
+func (s *AttachmentService) Upload(ctx context.Context, userId, itemId int, filename, contentType string, r io.Reader) (int, error) {
+	attachment := model.Attachment{Filename: filename, ContentType: contentType}
+	if err := attachment.Validate(); err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.CanWrite(ctx, userId, itemId); err != nil {
+		return 0, err
+	}
+
+	key, err := newBlobKey()
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := s.blobs.Put(key, io.LimitReader(r, s.maxSize+1))
+	if err != nil {
+		return 0, err
+	}
+
+	if size > s.maxSize {
+		deleteBlobs(s.blobs, []string{key})
+		return 0, fmt.Errorf("%w, the limit is %d bytes", model.ErrAttachmentTooLarge, s.maxSize)
+	}
+
+	attachment.Size = size
+	attachment.BlobKey = key
+	id, err := s.repo.Create(ctx, userId, itemId, attachment)
+	if err != nil {
+		deleteBlobs(s.blobs, []string{key})
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *AttachmentService) GetAll(ctx context.Context, userId, itemId int) ([]model.Attachment, error) {
+	attachments, err := s.repo.GetAll(ctx, userId, itemId)
+	if attachments == nil {
+		attachments = make([]model.Attachment, 0)
+	}
+	return attachments, err
+}
+
+// Open returns the metadata of an attachment and its contents, which the
+// caller must close.
+func (s *AttachmentService) Open(ctx context.Context, userId, itemId, attachmentId int) (model.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetById(ctx, userId, itemId, attachmentId)
+	if err != nil {
+		return attachment, nil, err
+	}
+
+	blob, err := s.blobs.Open(attachment.BlobKey)
+	return attachment, blob, err
+}
+
+func (s *AttachmentService) Delete(ctx context.Context, userId, itemId, attachmentId int) error {
+	blobKey, err := s.repo.Delete(ctx, userId, itemId, attachmentId)
+	if err != nil {
+		return err
+	}
+
+	deleteBlobs(s.blobs, []string{blobKey})
+	return nil
+}
+
+func newBlobKey() (string, error) {
+	b := make([]byte, blobKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// deleteBlobs removes blobs whose metadata is already gone. Failures only leave
+// orphaned blobs behind, so they are logged rather than returned.
+func deleteBlobs(blobs storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := blobs.Delete(key); err != nil {
+			logrus.Errorf("error deleting blob %s: %s", key, err.Error())
+		}
+	}
+}
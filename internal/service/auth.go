@@ -1,78 +1,281 @@
 package service
 
 import (
+	"TodoApp/internal/metrics"
 	"TodoApp/internal/model"
 	"TodoApp/internal/repository"
-	"crypto/sha1"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
 	"time"
 )
 
-const (
-	salt       = "h3hfg93mc"
-	signingKey = "fjkslvnfk"
-	tokenTTL   = 12 * time.Hour
+const refreshTokenBytes = 32
+
+var (
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidCredentials = errors.New("invalid username or password")
 )
 
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	PasswordHasher  PasswordHasher
+	Keys            *KeySet
+}
+
 type AuthService struct {
-	repo repository.Authorization
+	repo        repository.Authorization
+	sessionRepo repository.Session
+	cfg         AuthConfig
+	// verifiers are tried in order to check passwords against hashes made by
+	// any hasher that was ever configured.
+	verifiers []PasswordHasher
 }
 
-func NewAuthService(repo repository.Authorization) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo repository.Authorization, sessionRepo repository.Session, cfg AuthConfig) *AuthService {
+	if cfg.PasswordHasher == nil {
+		cfg.PasswordHasher = NewArgon2idHasher()
+	}
+
+	return &AuthService{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		cfg:         cfg,
+		verifiers:   []PasswordHasher{cfg.PasswordHasher, NewArgon2idHasher(), NewBcryptHasher(bcrypt.DefaultCost), LegacySHA1Hasher{}},
+	}
 }
 
-func (s *AuthService) CreateUser(user model.User) (int, error) {
-	user.Password = generatePasswordHash(user.Password)
-	return s.repo.CreateUser(user)
+func (s *AuthService) CreateUser(ctx context.Context, user model.User) (int, error) {
+	hash, err := s.cfg.PasswordHasher.Hash(user.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	user.Password = hash
+	id, err := s.repo.CreateUser(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.UsersSignedUp.Inc()
+	return id, nil
 }
 
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
-	user, err := s.repo.GetUser(username, generatePasswordHash(password))
+func (s *AuthService) GenerateToken(ctx context.Context, username, password string) (model.Tokens, error) {
+	user, err := s.authenticate(ctx, username, password)
 	if err != nil {
-		return "", err
+		return model.Tokens{}, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		user.Id,
-	})
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return model.Tokens{}, err
+	}
 
-	return token.SignedString([]byte(signingKey))
+	sessionId, err := s.sessionRepo.Create(ctx, user.Id, hashToken(refreshToken), time.Now().Add(s.cfg.RefreshTokenTTL))
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	accessToken, err := s.newAccessToken(user.Id, sessionId)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	return model.Tokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. The refresh token
+// is rotated, so the presented one can not be used again. Using it again, or
+// twice at the same time, revokes the session, as the token may be stolen.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (model.Tokens, error) {
+	session, err := s.getActiveSession(ctx, refreshToken)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	err = s.sessionRepo.Rotate(ctx, session.Id, session.RefreshTokenHash, hashToken(newToken), time.Now().Add(s.cfg.RefreshTokenTTL))
+	if errors.Is(err, sql.ErrNoRows) {
+		// another refresh has rotated the token since it was read
+		return model.Tokens{}, s.revokeReused(ctx, session.RefreshTokenHash)
+	}
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	accessToken, err := s.newAccessToken(session.UserId, session.Id)
+	if err != nil {
+		return model.Tokens{}, err
+	}
+
+	return model.Tokens{AccessToken: accessToken, RefreshToken: newToken}, nil
+}
+
+// Logout revokes the session of the refresh token. Access tokens issued for the
+// session are rejected by ParseToken from then on.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.getActiveSession(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	return s.sessionRepo.Revoke(ctx, session.Id)
+}
+
+func (s *AuthService) JWKS() model.JWKSet {
+	return s.cfg.Keys.JWKS()
 }
 
 type tokenClaims struct {
 	jwt.StandardClaims
-	UserId int `json:"user_id"`
+	UserId    int `json:"user_id"`
+	SessionId int `json:"sid"`
 }
 
-func (s *AuthService) ParseToken(accessToken string) (int, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(signingKey), nil
-	})
-
+func (s *AuthService) ParseToken(ctx context.Context, accessToken string) (int, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, s.cfg.Keys.Keyfunc)
 	if err != nil {
 		return 0, err
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok || !token.Valid {
-		return 0, errors.New("invalid token")
+		return 0, ErrInvalidToken
+	}
+
+	session, err := s.sessionRepo.GetById(ctx, claims.SessionId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidToken
+		}
+		return 0, err
+	}
+
+	if session.RevokedAt != nil || session.UserId != claims.UserId {
+		return 0, ErrInvalidToken
 	}
 
 	return claims.UserId, nil
 }
 
-func generatePasswordHash(password string) string {
-	hash := sha1.New()
-	hash.Write([]byte(password))
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+// authenticate checks the password in Go rather than in SQL, because salted
+// hashes can not be compared there. Hashes made by an outdated hasher are
+// replaced with a fresh one while the plain password is at hand.
+func (s *AuthService) authenticate(ctx context.Context, username, password string) (model.User, error) {
+	user, err := s.repo.GetUser(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, ErrInvalidCredentials
+		}
+		return model.User{}, err
+	}
+
+	hasher, err := s.verifierFor(user.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	ok, err := hasher.Verify(password, user.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !ok {
+		return model.User{}, ErrInvalidCredentials
+	}
+
+	if !s.cfg.PasswordHasher.Supports(user.Password) || s.cfg.PasswordHasher.NeedsRehash(user.Password) {
+		if err = s.rehashPassword(ctx, user.Id, password); err != nil {
+			logrus.Errorf("error rehashing password of user %d: %s", user.Id, err.Error())
+		}
+	}
+
+	return user, nil
+}
+
+func (s *AuthService) verifierFor(encodedHash string) (PasswordHasher, error) {
+	for _, verifier := range s.verifiers {
+		if verifier.Supports(encodedHash) {
+			return verifier, nil
+		}
+	}
+
+	return nil, ErrUnknownHashFormat
+}
+
+func (s *AuthService) rehashPassword(ctx context.Context, userId int, password string) error {
+	hash, err := s.cfg.PasswordHasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpdatePasswordHash(ctx, userId, hash)
+}
+
+func (s *AuthService) newAccessToken(userId, sessionId int) (string, error) {
+	return s.cfg.Keys.Sign(&tokenClaims{
+		jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(s.cfg.AccessTokenTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		userId,
+		sessionId,
+	})
+}
+
+func (s *AuthService) getActiveSession(ctx context.Context, refreshToken string) (model.Session, error) {
+	session, err := s.sessionRepo.GetByRefreshTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Session{}, s.revokeReused(ctx, hashToken(refreshToken))
+		}
+		return model.Session{}, err
+	}
+
+	if !session.Active(time.Now()) {
+		return model.Session{}, ErrInvalidToken
+	}
+
+	return session, nil
+}
+
+// revokeReused revokes the session of a refresh token that has been rotated
+// already and returns ErrInvalidToken for the refresh that presented it.
+func (s *AuthService) revokeReused(ctx context.Context, refreshTokenHash string) error {
+	reused, err := s.sessionRepo.RevokeRotated(ctx, refreshTokenHash)
+	if err != nil {
+		return err
+	}
+
+	if reused {
+		logrus.Warn("rotated refresh token was used again, its session is revoked")
+	}
+
+	return ErrInvalidToken
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken keeps only a digest of refresh and personal access tokens in the
+// database, so a leaked table can not be used to authenticate.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type CommentService struct {
+	repo repository.Comment
+}
+
+func NewCommentService(repo repository.Comment) *CommentService {
+	return &CommentService{repo: repo}
+}
+
+func (s *CommentService) Create(ctx context.Context, userId, itemId int, comment model.Comment) (int, error) {
+	if err := comment.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, userId, itemId, comment, model.ParseMentions(comment.Body))
+}
+
+func (s *CommentService) GetAll(ctx context.Context, userId, itemId int) ([]model.Comment, error) {
+	comments, err := s.repo.GetAll(ctx, userId, itemId)
+	if comments == nil {
+		comments = make([]model.Comment, 0)
+	}
+	return comments, err
+}
+
+func (s *CommentService) Update(ctx context.Context, userId, itemId, commentId int, input model.UpdateCommentInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, userId, itemId, commentId, input, model.ParseMentions(input.Body))
+}
+
+func (s *CommentService) Delete(ctx context.Context, userId, itemId, commentId int) error {
+	return s.repo.Delete(ctx, userId, itemId, commentId)
+}
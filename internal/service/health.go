@@ -0,0 +1,104 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errShuttingDown = errors.New("server is shutting down")
+
+// MigrationChecker reports how many migrations are not applied yet.
+type MigrationChecker interface {
+	Pending(ctx context.Context) (int, error)
+}
+
+type HealthService struct {
+	repo         repository.Health
+	migrations   MigrationChecker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewHealthService(repo repository.Health, migrations MigrationChecker, timeout time.Duration) *HealthService {
+	return &HealthService{repo: repo, migrations: migrations, timeout: timeout}
+}
+
+// Ready runs the readiness checks concurrently, each with the configured
+// timeout.
+func (s *HealthService) Ready(ctx context.Context) model.Readiness {
+	checks := map[string]func(ctx context.Context) error{
+		"shutdown":   s.checkShutdown,
+		"database":   s.repo.Ping,
+		"migrations": s.checkMigrations,
+	}
+
+	readiness := model.Readiness{Status: model.HealthOK, Checks: make(map[string]model.HealthCheck, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			result := s.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			readiness.Checks[name] = result
+			if result.Status != model.HealthOK {
+				readiness.Status = model.HealthFail
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return readiness
+}
+
+// Shutdown makes the server report not ready, so load balancers stop sending
+// traffic before it stops.
+func (s *HealthService) Shutdown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *HealthService) run(ctx context.Context, check func(ctx context.Context) error) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := model.HealthCheck{
+		Status:  model.HealthOK,
+		Latency: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = model.HealthFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (s *HealthService) checkShutdown(context.Context) error {
+	if s.shuttingDown.Load() {
+		return errShuttingDown
+	}
+	return nil
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	pending, err := s.migrations.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("%d migrations are pending", pending)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+	"time"
+)
+
+type InvitationService struct {
+	repo repository.Invitation
+	ttl  time.Duration
+}
+
+func NewInvitationService(repo repository.Invitation, ttl time.Duration) *InvitationService {
+	return &InvitationService{repo: repo, ttl: ttl}
+}
+
+func (s *InvitationService) Create(ctx context.Context, userId, listId int, input model.InviteInput) (int, error) {
+	if err := model.ValidateRole(input.Role); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, userId, listId, input, time.Now().Add(s.ttl))
+}
+
+func (s *InvitationService) GetPending(ctx context.Context, userId int) ([]model.Invitation, error) {
+	invitations, err := s.repo.GetPending(ctx, userId)
+	if invitations == nil {
+		invitations = make([]model.Invitation, 0)
+	}
+	return invitations, err
+}
+
+func (s *InvitationService) Accept(ctx context.Context, userId, invitationId int) error {
+	return s.repo.Accept(ctx, userId, invitationId)
+}
+
+func (s *InvitationService) Decline(ctx context.Context, userId, invitationId int) error {
+	return s.repo.Decline(ctx, userId, invitationId)
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"os"
+	"sort"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minHMACSecretLength is the size of the SHA-256 output. Shorter HS256 secrets
+// can be guessed offline from any token.
+const minHMACSecretLength = 32
+
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+// signingMethodEdDSA adds Ed25519 signatures, which jwt-go does not ship.
+type signingMethodEdDSA struct{}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return AlgorithmEdDSA
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// KeyConfig describes one JWT key. HS256 keys read their secret from the
+// SecretEnv environment variable, asymmetric keys are PEM files. A key with
+// only a public key file can verify tokens but not sign them, which is how
+// retired keys stay valid until the tokens signed with them expire.
+type KeyConfig struct {
+	Id             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	SecretEnv      string `mapstructure:"secret_env"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	publishKey bool
+}
+
+// KeySet holds the key new tokens are signed with and every key that is still
+// accepted for verification, looked up by the "kid" header.
+type KeySet struct {
+	current *signingKey
+	keys    map[string]*signingKey
+}
+
+func NewKeySet(currentKeyId string, configs []KeyConfig) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*signingKey, len(configs))}
+
+	for _, cfg := range configs {
+		if cfg.Id == "" {
+			return nil, errors.New("jwt key id must be set")
+		}
+		if _, ok := set.keys[cfg.Id]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id: %s", cfg.Id)
+		}
+
+		key, err := loadSigningKey(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", cfg.Id, err)
+		}
+		set.keys[cfg.Id] = key
+	}
+
+	current, ok := set.keys[currentKeyId]
+	if !ok {
+		return nil, fmt.Errorf("signing jwt key %q is not configured", currentKeyId)
+	}
+	if current.signKey == nil {
+		return nil, fmt.Errorf("signing jwt key %q has no private key", currentKeyId)
+	}
+	set.current = current
+
+	return set, nil
+}
+
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.current.method, claims)
+	token.Header["kid"] = s.current.id
+	return token.SignedString(s.current.signKey)
+}
+
+// Keyfunc resolves the verification key of a token. The algorithm of the token
+// must match the one configured for the key, so a public key can never be
+// used as an HMAC secret.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no key id")
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.verifyKey, nil
+}
+
+// JWKS returns the public keys of the set. HMAC secrets are never published.
+func (s *KeySet) JWKS() model.JWKSet {
+	set := model.JWKSet{Keys: make([]model.JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		if !key.publishKey {
+			continue
+		}
+
+		jwk := model.JWK{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
+
+func loadSigningKey(cfg KeyConfig) (*signingKey, error) {
+	key := &signingKey{id: cfg.Id}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		secret := os.Getenv(cfg.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("environment variable %q is empty", cfg.SecretEnv)
+		}
+		if len(secret) < minHMACSecretLength {
+			return nil, fmt.Errorf("environment variable %q must hold at least %d bytes for HS256", cfg.SecretEnv, minHMACSecretLength)
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(secret)
+		key.verifyKey = []byte(secret)
+		return key, nil
+	case AlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		key.method = SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", cfg.Algorithm)
+	}
+
+	key.publishKey = true
+
+	if cfg.PrivateKeyFile != "" {
+		privateKey, err := readPrivateKey(cfg.PrivateKeyFile, cfg.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = privateKey
+
+		switch privateKey := privateKey.(type) {
+		case *rsa.PrivateKey:
+			key.verifyKey = &privateKey.PublicKey
+		case ed25519.PrivateKey:
+			key.verifyKey = privateKey.Public()
+		}
+		return key, nil
+	}
+
+	if cfg.PublicKeyFile == "" {
+		return nil, errors.New("either private_key_file or public_key_file must be set")
+	}
+
+	publicKey, err := readPublicKey(cfg.PublicKeyFile, cfg.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	key.verifyKey = publicKey
+
+	return key, nil
+}
+
+func readPrivateKey(path, algorithm string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == AlgorithmRS256 {
+		return jwt.ParseRSAPrivateKeyFromPEM(data)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := privateKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an Ed25519 key")
+	}
+
+	return edKey, nil
+}
+
+func readPublicKey(path, algorithm string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == AlgorithmRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an Ed25519 key")
+	}
+
+	return edKey, nil
+}
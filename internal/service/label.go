@@ -0,0 +1,49 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type LabelService struct {
+	repo repository.Label
+}
+
+func NewLabelService(repo repository.Label) *LabelService {
+	return &LabelService{repo: repo}
+}
+
+func (s *LabelService) Create(ctx context.Context, userId int, label model.Label) (int, error) {
+	if err := label.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, userId, label)
+}
+
+func (s *LabelService) GetAll(ctx context.Context, userId int) ([]model.Label, error) {
+	labels, err := s.repo.GetAll(ctx, userId)
+	if labels == nil {
+		labels = make([]model.Label, 0)
+	}
+	return labels, err
+}
+
+func (s *LabelService) Update(ctx context.Context, userId, labelId int, input model.UpdateLabelInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, userId, labelId, input)
+}
+
+func (s *LabelService) Delete(ctx context.Context, userId, labelId int) error {
+	return s.repo.Delete(ctx, userId, labelId)
+}
+
+func (s *LabelService) Attach(ctx context.Context, userId, itemId, labelId int) error {
+	return s.repo.Attach(ctx, userId, itemId, labelId)
+}
+
+func (s *LabelService) Detach(ctx context.Context, userId, itemId, labelId int) error {
+	return s.repo.Detach(ctx, userId, itemId, labelId)
+}
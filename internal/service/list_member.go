@@ -0,0 +1,34 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type ListMemberService struct {
+	repo repository.ListMember
+}
+
+func NewListMemberService(repo repository.ListMember) *ListMemberService {
+	return &ListMemberService{repo: repo}
+}
+
+func (s *ListMemberService) GetAll(ctx context.Context, userId, listId int) ([]model.ListMember, error) {
+	members, err := s.repo.GetAll(ctx, userId, listId)
+	if members == nil {
+		members = make([]model.ListMember, 0)
+	}
+	return members, err
+}
+
+func (s *ListMemberService) UpdateRole(ctx context.Context, userId, listId, memberId int, input model.UpdateMemberInput) error {
+	if err := model.ValidateRole(input.Role); err != nil {
+		return err
+	}
+	return s.repo.UpdateRole(ctx, userId, listId, memberId, input.Role)
+}
+
+func (s *ListMemberService) Delete(ctx context.Context, userId, listId, memberId int) error {
+	return s.repo.Delete(ctx, userId, listId, memberId)
+}
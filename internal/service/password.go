@@ -0,0 +1,183 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	HasherArgon2id = "argon2id"
+	HasherBcrypt   = "bcrypt"
+
+	argon2idSaltLength = 16
+	legacySalt         = "h3hfg93mc"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher produces self-describing hashes: the algorithm and its
+// parameters are encoded in the hash, so hashes made with different settings
+// can live side by side in the users table.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Supports reports whether the encoded hash was produced by this hasher.
+	Supports(encodedHash string) bool
+	Verify(password, encodedHash string) (bool, error)
+	// NeedsRehash reports whether the encoded hash was produced with settings
+	// other than the current ones.
+	NeedsRehash(encodedHash string) bool
+}
+
+func NewPasswordHasher(name string) (PasswordHasher, error) {
+	switch name {
+	case "", HasherArgon2id:
+		return NewArgon2idHasher(), nil
+	case HasherBcrypt:
+		return NewBcryptHasher(bcrypt.DefaultCost), nil
+	default:
+		return nil, fmt.Errorf("unknown password hasher: %s", name)
+	}
+}
+
+type Argon2idHasher struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	keyLen  uint32
+}
+
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{time: 1, memory: 64 * 1024, threads: 4, keyLen: 32}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.time, h.memory, h.threads, h.keyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.memory, h.time, h.threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Supports(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+func (h *Argon2idHasher) Verify(password, encodedHash string) (bool, error) {
+	params, salt, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, _, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params.time != h.time || params.memory != h.memory || params.threads != h.threads || uint32(len(key)) != h.keyLen
+}
+
+func decodeArgon2idHash(encodedHash string) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+
+	// "$argon2id$v=19$m=65536,t=1,p=4$<salt>$<key>" splits into 6 parts, the first one is empty
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != HasherArgon2id {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, err
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
+
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h *BcryptHasher) Supports(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") || strings.HasPrefix(encodedHash, "$2b$") || strings.HasPrefix(encodedHash, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(password, encodedHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != h.cost
+}
+
+// LegacySHA1Hasher verifies hashes made before hashes became self-describing:
+// hex of the global salt followed by an unsalted SHA-1 digest. It is only kept
+// around to upgrade such hashes on sign in and must not be used to hash.
+type LegacySHA1Hasher struct{}
+
+func (h LegacySHA1Hasher) Hash(string) (string, error) {
+	return "", errors.New("legacy sha1 hashes must not be created")
+}
+
+func (h LegacySHA1Hasher) Supports(encodedHash string) bool {
+	return !strings.HasPrefix(encodedHash, "$")
+}
+
+func (h LegacySHA1Hasher) Verify(password, encodedHash string) (bool, error) {
+	return subtle.ConstantTimeCompare([]byte(legacyPasswordHash(password)), []byte(encodedHash)) == 1, nil
+}
+
+func (h LegacySHA1Hasher) NeedsRehash(string) bool {
+	return true
+}
+
+func legacyPasswordHash(password string) string {
+	hash := sha1.New()
+	hash.Write([]byte(password))
+	return fmt.Sprintf("%x", hash.Sum([]byte(legacySalt)))
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type SavedFilterService struct {
+	repo     repository.SavedFilter
+	itemRepo repository.TodoItem
+}
+
+func NewSavedFilterService(repo repository.SavedFilter, itemRepo repository.TodoItem) *SavedFilterService {
+	return &SavedFilterService{repo: repo, itemRepo: itemRepo}
+}
+
+func (s *SavedFilterService) Create(ctx context.Context, userId int, filter model.SavedFilter) (int, error) {
+	if err := filter.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, userId, filter)
+}
+
+func (s *SavedFilterService) GetAll(ctx context.Context, userId int) ([]model.SavedFilter, error) {
+	filters, err := s.repo.GetAll(ctx, userId)
+	if filters == nil {
+		filters = make([]model.SavedFilter, 0)
+	}
+	return filters, err
+}
+
+func (s *SavedFilterService) GetById(ctx context.Context, userId, filterId int) (model.SavedFilter, error) {
+	return s.repo.GetById(ctx, userId, filterId)
+}
+
+func (s *SavedFilterService) Update(ctx context.Context, userId, filterId int, input model.UpdateFilterInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, userId, filterId, input)
+}
+
+func (s *SavedFilterService) Delete(ctx context.Context, userId, filterId int) error {
+	return s.repo.Delete(ctx, userId, filterId)
+}
+
+// GetItems runs the saved filter like a list over all lists of the user.
+func (s *SavedFilterService) GetItems(ctx context.Context, userId, filterId int, page model.PageQuery) ([]model.TodoItem, string, error) {
+	if err := page.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	filter, err := s.repo.GetById(ctx, userId, filterId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items, next, err := s.itemRepo.GetAllByFilter(ctx, userId, filter.Expression, page)
+	if items == nil {
+		items = make([]model.TodoItem, 0)
+	}
+	return items, next, err
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type SearchService struct {
+	repo repository.Search
+}
+
+func NewSearchService(repo repository.Search) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) Search(ctx context.Context, userId int, query model.SearchQuery) ([]model.SearchResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	if query.Limit == 0 {
+		query.Limit = model.DefaultSearchLimit
+	}
+
+	results, err := s.repo.Search(ctx, userId, query.Query, query.Limit)
+	if results == nil {
+		results = make([]model.SearchResult, 0)
+	}
+	return results, err
+}
@@ -3,40 +3,176 @@ package service
 import (
 	"TodoApp/internal/model"
 	"TodoApp/internal/repository"
+	"TodoApp/internal/storage"
+	"context"
+	"io"
+	"time"
 )
 
+type Config struct {
+	Auth          AuthConfig
+	InvitationTTL time.Duration
+	// TrashRetention is how long deleted lists and items stay in the trash.
+	TrashRetention time.Duration
+	// Blobs keeps the contents of attachments, MaxAttachmentSize limits their
+	// size in bytes.
+	Blobs             storage.BlobStore
+	MaxAttachmentSize int64
+	// Migrations and HealthTimeout are used by the readiness checks.
+	Migrations    MigrationChecker
+	HealthTimeout time.Duration
+}
+
 type Authorization interface {
-	CreateUser(user model.User) (int, error)
-	GenerateToken(username, password string) (string, error)
-	ParseToken(token string) (int, error)
+	CreateUser(ctx context.Context, user model.User) (int, error)
+	GenerateToken(ctx context.Context, username, password string) (model.Tokens, error)
+	RefreshToken(ctx context.Context, refreshToken string) (model.Tokens, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ParseToken(ctx context.Context, token string) (int, error)
+	JWKS() model.JWKSet
+}
+
+type Token interface {
+	Create(ctx context.Context, userId int, input model.CreateTokenInput) (model.CreatedToken, error)
+	GetAll(ctx context.Context, userId int) ([]model.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userId, tokenId int) error
+	Authenticate(ctx context.Context, token string) (model.PersonalAccessToken, error)
 }
 
 type TodoList interface {
-	CreateList(userId int, list model.TodoList) (int, error)
-	GetAll(userId int) ([]model.TodoList, error)
-	GetById(userId, listId int) (model.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, updateRequest model.UpdateListInput) error
+	CreateList(ctx context.Context, userId int, list model.TodoList) (int, error)
+	GetAll(ctx context.Context, userId int, filter model.ListFilter, page model.PageQuery) ([]model.TodoList, string, error)
+	GetById(ctx context.Context, userId, listId int) (model.TodoList, error)
+	Reorder(ctx context.Context, userId int, input model.ReorderInput) error
+	Archive(ctx context.Context, userId, listId int) error
+	Unarchive(ctx context.Context, userId, listId int) error
+	Delete(ctx context.Context, userId, listId int) error
+	Update(ctx context.Context, userId, listId int, updateRequest model.UpdateListInput) error
+}
+
+type ListMember interface {
+	GetAll(ctx context.Context, userId, listId int) ([]model.ListMember, error)
+	UpdateRole(ctx context.Context, userId, listId, memberId int, input model.UpdateMemberInput) error
+	Delete(ctx context.Context, userId, listId, memberId int) error
+}
+
+type Invitation interface {
+	Create(ctx context.Context, userId, listId int, input model.InviteInput) (int, error)
+	GetPending(ctx context.Context, userId int) ([]model.Invitation, error)
+	Accept(ctx context.Context, userId, invitationId int) error
+	Decline(ctx context.Context, userId, invitationId int) error
 }
 
 type TodoItem interface {
-	Create(userId, listId int, todoItem model.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]model.TodoItem, error)
-	GetById(userId, itemId int) (model.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, updateItemInput model.UpdateItemInput) error
+	Create(ctx context.Context, userId, listId int, todoItem model.TodoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error)
+	Reorder(ctx context.Context, userId, listId int, input model.ReorderInput) error
+	Move(ctx context.Context, userId, itemId int, input model.MoveItemInput) error
+	Copy(ctx context.Context, userId, itemId int, input model.MoveItemInput) (int, error)
+	GetAllByUser(ctx context.Context, userId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error)
+	GetById(ctx context.Context, userId, itemId int) (model.TodoItem, error)
+	Delete(ctx context.Context, userId, itemId int) error
+	Update(ctx context.Context, userId, itemId int, updateItemInput model.UpdateItemInput) error
+}
+
+type Subtask interface {
+	Create(ctx context.Context, userId, itemId int, subtask model.Subtask) (int, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]model.Subtask, error)
+	Update(ctx context.Context, userId, itemId, subtaskId int, input model.UpdateSubtaskInput) error
+	Delete(ctx context.Context, userId, itemId, subtaskId int) error
+	Reorder(ctx context.Context, userId, itemId int, input model.ReorderSubtasksInput) error
+}
+
+type Comment interface {
+	Create(ctx context.Context, userId, itemId int, comment model.Comment) (int, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]model.Comment, error)
+	Update(ctx context.Context, userId, itemId, commentId int, input model.UpdateCommentInput) error
+	Delete(ctx context.Context, userId, itemId, commentId int) error
+}
+
+type Attachment interface {
+	Upload(ctx context.Context, userId, itemId int, filename, contentType string, r io.Reader) (int, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]model.Attachment, error)
+	Open(ctx context.Context, userId, itemId, attachmentId int) (model.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, userId, itemId, attachmentId int) error
+}
+
+type Label interface {
+	Create(ctx context.Context, userId int, label model.Label) (int, error)
+	GetAll(ctx context.Context, userId int) ([]model.Label, error)
+	Update(ctx context.Context, userId, labelId int, input model.UpdateLabelInput) error
+	Delete(ctx context.Context, userId, labelId int) error
+	Attach(ctx context.Context, userId, itemId, labelId int) error
+	Detach(ctx context.Context, userId, itemId, labelId int) error
+}
+
+type SavedFilter interface {
+	Create(ctx context.Context, userId int, filter model.SavedFilter) (int, error)
+	GetAll(ctx context.Context, userId int) ([]model.SavedFilter, error)
+	GetById(ctx context.Context, userId, filterId int) (model.SavedFilter, error)
+	Update(ctx context.Context, userId, filterId int, input model.UpdateFilterInput) error
+	Delete(ctx context.Context, userId, filterId int) error
+	GetItems(ctx context.Context, userId, filterId int, page model.PageQuery) ([]model.TodoItem, string, error)
+}
+
+type Search interface {
+	Search(ctx context.Context, userId int, query model.SearchQuery) ([]model.SearchResult, error)
+}
+
+type Trash interface {
+	GetAll(ctx context.Context, userId int) ([]model.TrashEntry, error)
+	Restore(ctx context.Context, userId int, entryType string, id int) error
+	Purge(ctx context.Context, userId int, entryType string, id int) error
+	PurgeExpired(ctx context.Context) (int64, error)
+	RunPurge(ctx context.Context, interval time.Duration)
+}
+
+type Activity interface {
+	GetByList(ctx context.Context, userId, listId int, page model.PageQuery) ([]model.Activity, string, error)
+	GetByItem(ctx context.Context, userId, itemId int, page model.PageQuery) ([]model.Activity, string, error)
+}
+
+type Health interface {
+	Ready(ctx context.Context) model.Readiness
+	Shutdown()
 }
 
 type Service struct {
 	Authorization
+	Token
 	TodoList
+	ListMember
+	Invitation
 	TodoItem
+	Subtask
+	Comment
+	Attachment
+	Label
+	SavedFilter
+	Search
+	Trash
+	Activity
+	Health
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository, cfg Config) *Service {
+	todoItem := todoItemTracing{NewTodoItemService(repos.TodoItem)}
+
 	return &Service{
-		Authorization: NewAuthService(repos.Authorization),
-		TodoList:      NewTodoListService(repos.TodoList),
-		TodoItem:      NewTodoItemService(repos.TodoItem, repos.TodoList),
+		Authorization: authorizationTracing{NewAuthService(repos.Authorization, repos.Session, cfg.Auth)},
+		Token:         tokenTracing{NewTokenService(repos.Token)},
+		TodoList:      todoListTracing{NewTodoListService(repos.TodoList)},
+		ListMember:    listMemberTracing{NewListMemberService(repos.ListMember)},
+		Invitation:    invitationTracing{NewInvitationService(repos.Invitation, cfg.InvitationTTL)},
+		TodoItem:      todoItem,
+		Subtask:       subtaskTracing{NewSubtaskService(repos.Subtask, todoItem)},
+		Comment:       commentTracing{NewCommentService(repos.Comment)},
+		Attachment:    attachmentTracing{NewAttachmentService(repos.Attachment, cfg.Blobs, cfg.MaxAttachmentSize)},
+		Label:         labelTracing{NewLabelService(repos.Label)},
+		SavedFilter:   savedFilterTracing{NewSavedFilterService(repos.SavedFilter, repos.TodoItem)},
+		Search:        searchTracing{NewSearchService(repos.Search)},
+		Trash:         trashTracing{NewTrashService(repos.Trash, cfg.Blobs, cfg.TrashRetention)},
+		Activity:      activityTracing{NewActivityService(repos.Activity)},
+		Health:        NewHealthService(repos.Health, cfg.Migrations, cfg.HealthTimeout),
 	}
 }
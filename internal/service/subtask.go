@@ -0,0 +1,77 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+)
+
+type SubtaskService struct {
+	repo  repository.Subtask
+	items TodoItem
+}
+
+func NewSubtaskService(repo repository.Subtask, items TodoItem) *SubtaskService {
+	return &SubtaskService{repo: repo, items: items}
+}
+
+func (s *SubtaskService) Create(ctx context.Context, userId, itemId int, subtask model.Subtask) (int, error) {
+	return s.repo.Create(ctx, userId, itemId, subtask)
+}
+
+func (s *SubtaskService) GetAll(ctx context.Context, userId, itemId int) ([]model.Subtask, error) {
+	subtasks, err := s.repo.GetAll(ctx, userId, itemId)
+	if subtasks == nil {
+		subtasks = make([]model.Subtask, 0)
+	}
+	return subtasks, err
+}
+
+// Update changes a subtask. Checking off the last open subtask completes the
+// item as well.
+func (s *SubtaskService) Update(ctx context.Context, userId, itemId, subtaskId int, input model.UpdateSubtaskInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, userId, itemId, subtaskId, input); err != nil {
+		return err
+	}
+
+	if input.Done == nil || !*input.Done {
+		return nil
+	}
+
+	return s.completeItem(ctx, userId, itemId)
+}
+
+// completeItem marks the item done once all its subtasks are. It goes through
+// TodoItem.Update like any other completion, so the change is logged and
+// counted and a recurring item gets its next occurrence.
+func (s *SubtaskService) completeItem(ctx context.Context, userId, itemId int) error {
+	item, err := s.items.GetById(ctx, userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	if item.Done {
+		return nil
+	}
+
+	for _, subtask := range item.Subtasks {
+		if !subtask.Done {
+			return nil
+		}
+	}
+
+	done := true
+	return s.items.Update(ctx, userId, itemId, model.UpdateItemInput{Done: &done})
+}
+
+func (s *SubtaskService) Delete(ctx context.Context, userId, itemId, subtaskId int) error {
+	return s.repo.Delete(ctx, userId, itemId, subtaskId)
+}
+
+func (s *SubtaskService) Reorder(ctx context.Context, userId, itemId int, input model.ReorderSubtasksInput) error {
+	return s.repo.Reorder(ctx, userId, itemId, input.Ids)
+}
@@ -1,45 +1,195 @@
 package service
 
 import (
+	"TodoApp/internal/metrics"
 	"TodoApp/internal/model"
+	"TodoApp/internal/recurrence"
 	"TodoApp/internal/repository"
+	"context"
+	"time"
 )
 
 type TodoItemService struct {
-	repo     repository.TodoItem
-	listRepo repository.TodoList
+	repo repository.TodoItem
 }
 
-func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList) *TodoItemService {
-	return &TodoItemService{repo: repo, listRepo: listRepo}
+func NewTodoItemService(repo repository.TodoItem) *TodoItemService {
+	return &TodoItemService{repo: repo}
 }
 
-func (s *TodoItemService) Create(userId, listId int, todoItem model.TodoItem) (int, error) {
-	_, err := s.listRepo.GetById(userId, listId)
+func (s *TodoItemService) Create(ctx context.Context, userId, listId int, todoItem model.TodoItem) (int, error) {
+	if err := todoItem.Validate(); err != nil {
+		return 0, err
+	}
+
+	if todoItem.Priority == "" {
+		todoItem.Priority = model.PriorityNone
+	}
+
+	todoItem.Recurrence = normalizeRecurrence(todoItem.Recurrence)
+	todoItem.Occurrence = 1
+
+	id, err := s.repo.Create(ctx, userId, listId, todoItem)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.ItemsCreated.Inc()
+	return id, nil
+}
+
+func (s *TodoItemService) GetAll(ctx context.Context, userId, listId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error) {
+	if err := page.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	items, next, err := s.repo.GetAll(ctx, userId, listId, filter, page)
+	if items == nil {
+		items = make([]model.TodoItem, 0)
+	}
+	return items, next, err
+}
+
+func (s *TodoItemService) Reorder(ctx context.Context, userId, listId int, input model.ReorderInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.Reorder(ctx, userId, listId, input)
+}
+
+func (s *TodoItemService) Move(ctx context.Context, userId, itemId int, input model.MoveItemInput) error {
+	return s.repo.Move(ctx, userId, itemId, input.ListId)
+}
+
+// Copy may copy items out of archived lists, but not into them.
+func (s *TodoItemService) Copy(ctx context.Context, userId, itemId int, input model.MoveItemInput) (int, error) {
+	id, err := s.repo.Copy(ctx, userId, itemId, input.ListId)
 	if err != nil {
-		// list does not exist or does not belong to user
 		return 0, err
 	}
 
-	return s.repo.Create(listId, todoItem)
+	metrics.ItemsCreated.Inc()
+	return id, nil
 }
 
-func (s *TodoItemService) GetAll(userId, listId int) ([]model.TodoItem, error) {
-	items, err := s.repo.GetAll(userId, listId)
+func (s *TodoItemService) GetAllByUser(ctx context.Context, userId int, filter model.ItemFilter, page model.PageQuery) ([]model.TodoItem, string, error) {
+	if err := page.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	items, next, err := s.repo.GetAllByUser(ctx, userId, filter, page)
 	if items == nil {
 		items = make([]model.TodoItem, 0)
 	}
-	return items, err
+	return items, next, err
+}
+
+func (s *TodoItemService) GetById(ctx context.Context, userId, itemId int) (model.TodoItem, error) {
+	return s.repo.GetById(ctx, userId, itemId)
+}
+
+func (s *TodoItemService) Delete(ctx context.Context, userId, itemId int) error {
+	return s.repo.Delete(ctx, userId, itemId)
 }
 
-func (s *TodoItemService) GetById(userId, itemId int) (model.TodoItem, error) {
-	return s.repo.GetById(userId, itemId)
+func (s *TodoItemService) Update(ctx context.Context, userId, itemId int, updateItemInput model.UpdateItemInput) error {
+	if err := updateItemInput.Validate(); err != nil {
+		return err
+	}
+
+	completing := updateItemInput.Done != nil && *updateItemInput.Done
+	wasDone := false
+	if completing {
+		item, err := s.repo.GetById(ctx, userId, itemId)
+		if err != nil {
+			return err
+		}
+		wasDone = item.Done
+	}
+
+	updateItemInput.Recurrence.String = normalizeRecurrence(updateItemInput.Recurrence.String)
+	if err := s.repo.Update(ctx, userId, itemId, updateItemInput); err != nil {
+		return err
+	}
+
+	if completing {
+		if !wasDone {
+			metrics.ItemsCompleted.Inc()
+		}
+		return s.scheduleNext(ctx, userId, itemId)
+	}
+
+	return nil
 }
 
-func (s *TodoItemService) Delete(userId, itemId int) error {
-	return s.repo.Delete(userId, itemId)
+// scheduleNext creates the next occurrence of a completed recurring item, due
+// one step of its rule after its own due date, or after now if it has none.
+// The start date keeps its distance to the due date.
+func (s *TodoItemService) scheduleNext(ctx context.Context, userId, itemId int) error {
+	item, err := s.repo.GetById(ctx, userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	if item.Recurrence == nil || item.Recurred {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(*item.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	prev := time.Now().UTC()
+	if item.DueAt != nil {
+		prev = *item.DueAt
+	}
+
+	dueAt, ok := rule.Next(prev, item.Occurrence)
+	if !ok {
+		// the series has ended
+		return nil
+	}
+
+	next := model.TodoItem{
+		Title:       item.Title,
+		Description: item.Description,
+		Priority:    item.Priority,
+		Recurrence:  item.Recurrence,
+		Occurrence:  item.Occurrence + 1,
+		DueAt:       &dueAt,
+	}
+
+	if item.StartAt != nil && item.DueAt != nil {
+		startAt := dueAt.Add(item.StartAt.Sub(*item.DueAt))
+		next.StartAt = &startAt
+	}
+
+	nextId, err := s.repo.CreateNextOccurrence(ctx, userId, itemId, next)
+	if err != nil {
+		return err
+	}
+
+	// 0 means a concurrent update has created the next occurrence already
+	if nextId != 0 {
+		metrics.ItemsCreated.Inc()
+	}
+	return nil
 }
 
-func (s *TodoItemService) Update(userId, itemId int, updateItemInput model.UpdateItemInput) error {
-	return s.repo.Update(userId, itemId, updateItemInput)
+// normalizeRecurrence stores rules in canonical form. The rule must have been
+// validated already.
+func normalizeRecurrence(rrule *string) *string {
+	if rrule == nil {
+		return nil
+	}
+
+	rule, err := recurrence.Parse(*rrule)
+	if err != nil {
+		return rrule
+	}
+
+	normalized := rule.String()
+	return &normalized
 }
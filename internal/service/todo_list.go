@@ -3,6 +3,7 @@ package service
 import (
 	"TodoApp/internal/model"
 	"TodoApp/internal/repository"
+	"context"
 )
 
 type TodoListService struct {
@@ -13,29 +14,48 @@ func NewTodoListService(repo repository.TodoList) *TodoListService {
 	return &TodoListService{repo: repo}
 }
 
-func (s *TodoListService) CreateList(userId int, list model.TodoList) (int, error) {
-	return s.repo.Create(userId, list)
+func (s *TodoListService) CreateList(ctx context.Context, userId int, list model.TodoList) (int, error) {
+	return s.repo.Create(ctx, userId, list)
 }
 
-func (s *TodoListService) GetAll(userId int) ([]model.TodoList, error) {
-	lists, err := s.repo.GetAll(userId)
+func (s *TodoListService) GetAll(ctx context.Context, userId int, filter model.ListFilter, page model.PageQuery) ([]model.TodoList, string, error) {
+	if err := page.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	lists, next, err := s.repo.GetAll(ctx, userId, filter, page)
 	if lists == nil {
 		lists = make([]model.TodoList, 0)
 	}
-	return lists, err
+	return lists, next, err
+}
+
+func (s *TodoListService) GetById(ctx context.Context, userId, listId int) (model.TodoList, error) {
+	return s.repo.GetById(ctx, userId, listId)
+}
+
+func (s *TodoListService) Reorder(ctx context.Context, userId int, input model.ReorderInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Reorder(ctx, userId, input)
+}
+
+func (s *TodoListService) Archive(ctx context.Context, userId, listId int) error {
+	return s.repo.SetArchived(ctx, userId, listId, true)
 }
 
-func (s *TodoListService) GetById(userId, listId int) (model.TodoList, error) {
-	return s.repo.GetById(userId, listId)
+func (s *TodoListService) Unarchive(ctx context.Context, userId, listId int) error {
+	return s.repo.SetArchived(ctx, userId, listId, false)
 }
 
-func (s *TodoListService) Delete(userId, listId int) error {
-	return s.repo.Delete(userId, listId)
+func (s *TodoListService) Delete(ctx context.Context, userId, listId int) error {
+	return s.repo.Delete(ctx, userId, listId)
 }
 
-func (s *TodoListService) Update(userId, listId int, updateRequest model.UpdateListInput) error {
+func (s *TodoListService) Update(ctx context.Context, userId, listId int, updateRequest model.UpdateListInput) error {
 	if err := updateRequest.Validate(); err != nil {
 		return err
 	}
-	return s.repo.Update(userId, listId, updateRequest)
+	return s.repo.Update(ctx, userId, listId, updateRequest)
 }
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	// PersonalAccessTokenPrefix tells personal access tokens apart from JWTs in
+	// the Authorization header and makes leaked tokens easy to scan for.
+	PersonalAccessTokenPrefix = "tdp_"
+
+	personalAccessTokenBytes = 32
+)
+
+type TokenService struct {
+	repo repository.Token
+}
+
+func NewTokenService(repo repository.Token) *TokenService {
+	return &TokenService{repo: repo}
+}
+
+func (s *TokenService) Create(ctx context.Context, userId int, input model.CreateTokenInput) (model.CreatedToken, error) {
+	if err := input.Validate(); err != nil {
+		return model.CreatedToken{}, err
+	}
+
+	b := make([]byte, personalAccessTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return model.CreatedToken{}, err
+	}
+	plain := PersonalAccessTokenPrefix + hex.EncodeToString(b)
+
+	token, err := s.repo.Create(ctx, model.PersonalAccessToken{
+		UserId:    userId,
+		Name:      input.Name,
+		Scope:     input.Scope,
+		TokenHash: hashToken(plain),
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return model.CreatedToken{}, err
+	}
+
+	return model.CreatedToken{PersonalAccessToken: token, Token: plain}, nil
+}
+
+func (s *TokenService) GetAll(ctx context.Context, userId int) ([]model.PersonalAccessToken, error) {
+	tokens, err := s.repo.GetAll(ctx, userId)
+	if tokens == nil {
+		tokens = make([]model.PersonalAccessToken, 0)
+	}
+	return tokens, err
+}
+
+func (s *TokenService) Revoke(ctx context.Context, userId, tokenId int) error {
+	return s.repo.Revoke(ctx, userId, tokenId)
+}
+
+func (s *TokenService) Authenticate(ctx context.Context, token string) (model.PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return model.PersonalAccessToken{}, ErrInvalidToken
+	}
+
+	pat, err := s.repo.Use(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PersonalAccessToken{}, ErrInvalidToken
+		}
+		return model.PersonalAccessToken{}, err
+	}
+
+	return pat, nil
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/tracing"
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"time"
+)
+
+var tracer = otel.Tracer(tracing.ServiceName + "/service")
+
+// endSpan ends a span of a service call and marks it as failed if the call
+// returned an error. The xxxTracing types below wrap the services to start a
+// span for every call that takes a context, NewService applies them.
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+type authorizationTracing struct {
+	service Authorization
+}
+
+func (s authorizationTracing) CreateUser(ctx context.Context, user model.User) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Authorization.CreateUser")
+	defer endSpan(span, &err)
+	return s.service.CreateUser(ctx, user)
+}
+
+func (s authorizationTracing) GenerateToken(ctx context.Context, username, password string) (_ model.Tokens, err error) {
+	ctx, span := tracer.Start(ctx, "Authorization.GenerateToken")
+	defer endSpan(span, &err)
+	return s.service.GenerateToken(ctx, username, password)
+}
+
+func (s authorizationTracing) RefreshToken(ctx context.Context, refreshToken string) (_ model.Tokens, err error) {
+	ctx, span := tracer.Start(ctx, "Authorization.RefreshToken")
+	defer endSpan(span, &err)
+	return s.service.RefreshToken(ctx, refreshToken)
+}
+
+func (s authorizationTracing) Logout(ctx context.Context, refreshToken string) (err error) {
+	ctx, span := tracer.Start(ctx, "Authorization.Logout")
+	defer endSpan(span, &err)
+	return s.service.Logout(ctx, refreshToken)
+}
+
+func (s authorizationTracing) ParseToken(ctx context.Context, token string) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Authorization.ParseToken")
+	defer endSpan(span, &err)
+	return s.service.ParseToken(ctx, token)
+}
+
+func (s authorizationTracing) JWKS() model.JWKSet {
+	return s.service.JWKS()
+}
+
+type tokenTracing struct {
+	service Token
+}
+
+func (s tokenTracing) Create(ctx context.Context, userId int, input model.CreateTokenInput) (_ model.CreatedToken, err error) {
+	ctx, span := tracer.Start(ctx, "Token.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, input)
+}
+
+func (s tokenTracing) GetAll(ctx context.Context, userId int) (_ []model.PersonalAccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "Token.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId)
+}
+
+func (s tokenTracing) Revoke(ctx context.Context, userId, tokenId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Token.Revoke")
+	defer endSpan(span, &err)
+	return s.service.Revoke(ctx, userId, tokenId)
+}
+
+func (s tokenTracing) Authenticate(ctx context.Context, token string) (_ model.PersonalAccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "Token.Authenticate")
+	defer endSpan(span, &err)
+	return s.service.Authenticate(ctx, token)
+}
+
+type todoListTracing struct {
+	service TodoList
+}
+
+func (s todoListTracing) CreateList(ctx context.Context, userId int, list model.TodoList) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.CreateList")
+	defer endSpan(span, &err)
+	return s.service.CreateList(ctx, userId, list)
+}
+
+func (s todoListTracing) GetAll(ctx context.Context, userId int, filter model.ListFilter, page model.PageQuery) (_ []model.TodoList, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId, filter, page)
+}
+
+func (s todoListTracing) GetById(ctx context.Context, userId, listId int) (_ model.TodoList, err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.GetById")
+	defer endSpan(span, &err)
+	return s.service.GetById(ctx, userId, listId)
+}
+
+func (s todoListTracing) Reorder(ctx context.Context, userId int, input model.ReorderInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.Reorder")
+	defer endSpan(span, &err)
+	return s.service.Reorder(ctx, userId, input)
+}
+
+func (s todoListTracing) Archive(ctx context.Context, userId, listId int) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.Archive")
+	defer endSpan(span, &err)
+	return s.service.Archive(ctx, userId, listId)
+}
+
+func (s todoListTracing) Unarchive(ctx context.Context, userId, listId int) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.Unarchive")
+	defer endSpan(span, &err)
+	return s.service.Unarchive(ctx, userId, listId)
+}
+
+func (s todoListTracing) Delete(ctx context.Context, userId, listId int) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, listId)
+}
+
+func (s todoListTracing) Update(ctx context.Context, userId, listId int, updateRequest model.UpdateListInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoList.Update")
+	defer endSpan(span, &err)
+	return s.service.Update(ctx, userId, listId, updateRequest)
+}
+
+type listMemberTracing struct {
+	service ListMember
+}
+
+func (s listMemberTracing) GetAll(ctx context.Context, userId, listId int) (_ []model.ListMember, err error) {
+	ctx, span := tracer.Start(ctx, "ListMember.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId, listId)
+}
+
+func (s listMemberTracing) UpdateRole(ctx context.Context, userId, listId, memberId int, input model.UpdateMemberInput) (err error) {
+	ctx, span := tracer.Start(ctx, "ListMember.UpdateRole")
+	defer endSpan(span, &err)
+	return s.service.UpdateRole(ctx, userId, listId, memberId, input)
+}
+
+func (s listMemberTracing) Delete(ctx context.Context, userId, listId, memberId int) (err error) {
+	ctx, span := tracer.Start(ctx, "ListMember.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, listId, memberId)
+}
+
+type invitationTracing struct {
+	service Invitation
+}
+
+func (s invitationTracing) Create(ctx context.Context, userId, listId int, input model.InviteInput) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Invitation.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, listId, input)
+}
+
+func (s invitationTracing) GetPending(ctx context.Context, userId int) (_ []model.Invitation, err error) {
+	ctx, span := tracer.Start(ctx, "Invitation.GetPending")
+	defer endSpan(span, &err)
+	return s.service.GetPending(ctx, userId)
+}
+
+func (s invitationTracing) Accept(ctx context.Context, userId, invitationId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Invitation.Accept")
+	defer endSpan(span, &err)
+	return s.service.Accept(ctx, userId, invitationId)
+}
+
+func (s invitationTracing) Decline(ctx context.Context, userId, invitationId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Invitation.Decline")
+	defer endSpan(span, &err)
+	return s.service.Decline(ctx, userId, invitationId)
+}
+
+type todoItemTracing struct {
+	service TodoItem
+}
+
+func (s todoItemTracing) Create(ctx context.Context, userId, listId int, todoItem model.TodoItem) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, listId, todoItem)
+}
+
+func (s todoItemTracing) GetAll(ctx context.Context, userId, listId int, filter model.ItemFilter, page model.PageQuery) (_ []model.TodoItem, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId, listId, filter, page)
+}
+
+func (s todoItemTracing) Reorder(ctx context.Context, userId, listId int, input model.ReorderInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.Reorder")
+	defer endSpan(span, &err)
+	return s.service.Reorder(ctx, userId, listId, input)
+}
+
+func (s todoItemTracing) Move(ctx context.Context, userId, itemId int, input model.MoveItemInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.Move")
+	defer endSpan(span, &err)
+	return s.service.Move(ctx, userId, itemId, input)
+}
+
+func (s todoItemTracing) Copy(ctx context.Context, userId, itemId int, input model.MoveItemInput) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.Copy")
+	defer endSpan(span, &err)
+	return s.service.Copy(ctx, userId, itemId, input)
+}
+
+func (s todoItemTracing) GetAllByUser(ctx context.Context, userId int, filter model.ItemFilter, page model.PageQuery) (_ []model.TodoItem, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.GetAllByUser")
+	defer endSpan(span, &err)
+	return s.service.GetAllByUser(ctx, userId, filter, page)
+}
+
+func (s todoItemTracing) GetById(ctx context.Context, userId, itemId int) (_ model.TodoItem, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.GetById")
+	defer endSpan(span, &err)
+	return s.service.GetById(ctx, userId, itemId)
+}
+
+func (s todoItemTracing) Delete(ctx context.Context, userId, itemId int) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, itemId)
+}
+
+func (s todoItemTracing) Update(ctx context.Context, userId, itemId int, updateItemInput model.UpdateItemInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItem.Update")
+	defer endSpan(span, &err)
+	return s.service.Update(ctx, userId, itemId, updateItemInput)
+}
+
+type subtaskTracing struct {
+	service Subtask
+}
+
+func (s subtaskTracing) Create(ctx context.Context, userId, itemId int, subtask model.Subtask) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Subtask.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, itemId, subtask)
+}
+
+func (s subtaskTracing) GetAll(ctx context.Context, userId, itemId int) (_ []model.Subtask, err error) {
+	ctx, span := tracer.Start(ctx, "Subtask.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId, itemId)
+}
+
+func (s subtaskTracing) Update(ctx context.Context, userId, itemId, subtaskId int, input model.UpdateSubtaskInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Subtask.Update")
+	defer endSpan(span, &err)
+	return s.service.Update(ctx, userId, itemId, subtaskId, input)
+}
+
+func (s subtaskTracing) Delete(ctx context.Context, userId, itemId, subtaskId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Subtask.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, itemId, subtaskId)
+}
+
+func (s subtaskTracing) Reorder(ctx context.Context, userId, itemId int, input model.ReorderSubtasksInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Subtask.Reorder")
+	defer endSpan(span, &err)
+	return s.service.Reorder(ctx, userId, itemId, input)
+}
+
+type commentTracing struct {
+	service Comment
+}
+
+func (s commentTracing) Create(ctx context.Context, userId, itemId int, comment model.Comment) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Comment.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, itemId, comment)
+}
+
+func (s commentTracing) GetAll(ctx context.Context, userId, itemId int) (_ []model.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "Comment.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId, itemId)
+}
+
+func (s commentTracing) Update(ctx context.Context, userId, itemId, commentId int, input model.UpdateCommentInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Comment.Update")
+	defer endSpan(span, &err)
+	return s.service.Update(ctx, userId, itemId, commentId, input)
+}
+
+func (s commentTracing) Delete(ctx context.Context, userId, itemId, commentId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Comment.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, itemId, commentId)
+}
+
+type attachmentTracing struct {
+	service Attachment
+}
+
+func (s attachmentTracing) Upload(ctx context.Context, userId, itemId int, filename, contentType string, r io.Reader) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Attachment.Upload")
+	defer endSpan(span, &err)
+	return s.service.Upload(ctx, userId, itemId, filename, contentType, r)
+}
+
+func (s attachmentTracing) GetAll(ctx context.Context, userId, itemId int) (_ []model.Attachment, err error) {
+	ctx, span := tracer.Start(ctx, "Attachment.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId, itemId)
+}
+
+func (s attachmentTracing) Open(ctx context.Context, userId, itemId, attachmentId int) (_ model.Attachment, _ io.ReadCloser, err error) {
+	ctx, span := tracer.Start(ctx, "Attachment.Open")
+	defer endSpan(span, &err)
+	return s.service.Open(ctx, userId, itemId, attachmentId)
+}
+
+func (s attachmentTracing) Delete(ctx context.Context, userId, itemId, attachmentId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Attachment.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, itemId, attachmentId)
+}
+
+type labelTracing struct {
+	service Label
+}
+
+func (s labelTracing) Create(ctx context.Context, userId int, label model.Label) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Label.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, label)
+}
+
+func (s labelTracing) GetAll(ctx context.Context, userId int) (_ []model.Label, err error) {
+	ctx, span := tracer.Start(ctx, "Label.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId)
+}
+
+func (s labelTracing) Update(ctx context.Context, userId, labelId int, input model.UpdateLabelInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Label.Update")
+	defer endSpan(span, &err)
+	return s.service.Update(ctx, userId, labelId, input)
+}
+
+func (s labelTracing) Delete(ctx context.Context, userId, labelId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Label.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, labelId)
+}
+
+func (s labelTracing) Attach(ctx context.Context, userId, itemId, labelId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Label.Attach")
+	defer endSpan(span, &err)
+	return s.service.Attach(ctx, userId, itemId, labelId)
+}
+
+func (s labelTracing) Detach(ctx context.Context, userId, itemId, labelId int) (err error) {
+	ctx, span := tracer.Start(ctx, "Label.Detach")
+	defer endSpan(span, &err)
+	return s.service.Detach(ctx, userId, itemId, labelId)
+}
+
+type savedFilterTracing struct {
+	service SavedFilter
+}
+
+func (s savedFilterTracing) Create(ctx context.Context, userId int, filter model.SavedFilter) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "SavedFilter.Create")
+	defer endSpan(span, &err)
+	return s.service.Create(ctx, userId, filter)
+}
+
+func (s savedFilterTracing) GetAll(ctx context.Context, userId int) (_ []model.SavedFilter, err error) {
+	ctx, span := tracer.Start(ctx, "SavedFilter.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId)
+}
+
+func (s savedFilterTracing) GetById(ctx context.Context, userId, filterId int) (_ model.SavedFilter, err error) {
+	ctx, span := tracer.Start(ctx, "SavedFilter.GetById")
+	defer endSpan(span, &err)
+	return s.service.GetById(ctx, userId, filterId)
+}
+
+func (s savedFilterTracing) Update(ctx context.Context, userId, filterId int, input model.UpdateFilterInput) (err error) {
+	ctx, span := tracer.Start(ctx, "SavedFilter.Update")
+	defer endSpan(span, &err)
+	return s.service.Update(ctx, userId, filterId, input)
+}
+
+func (s savedFilterTracing) Delete(ctx context.Context, userId, filterId int) (err error) {
+	ctx, span := tracer.Start(ctx, "SavedFilter.Delete")
+	defer endSpan(span, &err)
+	return s.service.Delete(ctx, userId, filterId)
+}
+
+func (s savedFilterTracing) GetItems(ctx context.Context, userId, filterId int, page model.PageQuery) (_ []model.TodoItem, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "SavedFilter.GetItems")
+	defer endSpan(span, &err)
+	return s.service.GetItems(ctx, userId, filterId, page)
+}
+
+type searchTracing struct {
+	service Search
+}
+
+func (s searchTracing) Search(ctx context.Context, userId int, query model.SearchQuery) (_ []model.SearchResult, err error) {
+	ctx, span := tracer.Start(ctx, "Search.Search")
+	defer endSpan(span, &err)
+	return s.service.Search(ctx, userId, query)
+}
+
+type trashTracing struct {
+	service Trash
+}
+
+func (s trashTracing) GetAll(ctx context.Context, userId int) (_ []model.TrashEntry, err error) {
+	ctx, span := tracer.Start(ctx, "Trash.GetAll")
+	defer endSpan(span, &err)
+	return s.service.GetAll(ctx, userId)
+}
+
+func (s trashTracing) Restore(ctx context.Context, userId int, entryType string, id int) (err error) {
+	ctx, span := tracer.Start(ctx, "Trash.Restore")
+	defer endSpan(span, &err)
+	return s.service.Restore(ctx, userId, entryType, id)
+}
+
+func (s trashTracing) Purge(ctx context.Context, userId int, entryType string, id int) (err error) {
+	ctx, span := tracer.Start(ctx, "Trash.Purge")
+	defer endSpan(span, &err)
+	return s.service.Purge(ctx, userId, entryType, id)
+}
+
+func (s trashTracing) PurgeExpired(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Trash.PurgeExpired")
+	defer endSpan(span, &err)
+	return s.service.PurgeExpired(ctx)
+}
+
+func (s trashTracing) RunPurge(ctx context.Context, interval time.Duration) {
+	s.service.RunPurge(ctx, interval)
+}
+
+type activityTracing struct {
+	service Activity
+}
+
+func (s activityTracing) GetByList(ctx context.Context, userId, listId int, page model.PageQuery) (_ []model.Activity, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "Activity.GetByList")
+	defer endSpan(span, &err)
+	return s.service.GetByList(ctx, userId, listId, page)
+}
+
+func (s activityTracing) GetByItem(ctx context.Context, userId, itemId int, page model.PageQuery) (_ []model.Activity, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "Activity.GetByItem")
+	defer endSpan(span, &err)
+	return s.service.GetByItem(ctx, userId, itemId, page)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"TodoApp/internal/model"
+	"TodoApp/internal/repository"
+	"TodoApp/internal/storage"
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type TrashService struct {
+	repo      repository.Trash
+	blobs     storage.BlobStore
+	retention time.Duration
+}
+
+func NewTrashService(repo repository.Trash, blobs storage.BlobStore, retention time.Duration) *TrashService {
+	return &TrashService{repo: repo, blobs: blobs, retention: retention}
+}
+
+func (s *TrashService) GetAll(ctx context.Context, userId int) ([]model.TrashEntry, error) {
+	entries, err := s.repo.GetAll(ctx, userId)
+	if entries == nil {
+		entries = make([]model.TrashEntry, 0)
+	}
+	return entries, err
+}
+
+func (s *TrashService) Restore(ctx context.Context, userId int, entryType string, id int) error {
+	if err := model.ValidateTrashType(entryType); err != nil {
+		return err
+	}
+	return s.repo.Restore(ctx, userId, entryType, id)
+}
+
+func (s *TrashService) Purge(ctx context.Context, userId int, entryType string, id int) error {
+	if err := model.ValidateTrashType(entryType); err != nil {
+		return err
+	}
+
+	blobKeys, err := s.repo.Purge(ctx, userId, entryType, id)
+	if err != nil {
+		return err
+	}
+
+	deleteBlobs(s.blobs, blobKeys)
+	return nil
+}
+
+// PurgeExpired deletes everything that has been in the trash for longer than
+// the retention period and returns the number of deleted lists and items.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int64, error) {
+	purged, blobKeys, err := s.repo.PurgeExpired(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	deleteBlobs(s.blobs, blobKeys)
+	return purged, nil
+}
+
+// RunPurge empties expired trash right away and then once per interval until
+// the context is done. The interval must be positive.
+func (s *TrashService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired(ctx)
+		if err != nil {
+			logrus.Errorf("error purging trash: %s", err.Error())
+		} else if purged > 0 {
+			logrus.Infof("purged %d lists and items from trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
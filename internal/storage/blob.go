@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps the contents of attachments by key. Keys are generated by
+// the app and are safe to use as file or object names.
+type BlobStore interface {
+	// Put stores the contents of r under key and returns their size.
+	Put(key string, r io.Reader) (int64, error)
+	// Open returns the contents stored under key or ErrBlobNotFound.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the contents stored under key. Deleting a missing key
+	// is not an error.
+	Delete(key string) error
+}
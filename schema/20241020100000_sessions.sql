@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id                 SERIAL PRIMARY KEY,
+    user_id            INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    refresh_token_hash VARCHAR(255)                                NOT NULL UNIQUE,
+    expires_at         TIMESTAMP                                   NOT NULL,
+    created_at         TIMESTAMP                                   NOT NULL DEFAULT NOW(),
+    revoked_at         TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementEnd
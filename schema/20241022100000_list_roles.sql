@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users_lists
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'owner'
+        CHECK (role IN ('owner', 'editor', 'viewer'));
+
+ALTER TABLE users_lists
+    ALTER COLUMN role DROP DEFAULT;
+
+ALTER TABLE users_lists
+    ADD CONSTRAINT users_lists_user_id_list_id_key UNIQUE (user_id, list_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users_lists
+    DROP CONSTRAINT IF EXISTS users_lists_user_id_list_id_key;
+
+ALTER TABLE users_lists
+    DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS list_invitations
+(
+    id           SERIAL PRIMARY KEY,
+    list_id      INT REFERENCES todo_lists (id) ON DELETE CASCADE NOT NULL,
+    inviter_id   INT REFERENCES users (id) ON DELETE CASCADE      NOT NULL,
+    invitee_id   INT REFERENCES users (id) ON DELETE CASCADE      NOT NULL,
+    role         VARCHAR(16)                                      NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    status       VARCHAR(16)                                      NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at   TIMESTAMP                                        NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMP                                        NOT NULL,
+    responded_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS list_invitations_pending_idx ON list_invitations (list_id, invitee_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS list_invitations_invitee_id_idx ON list_invitations (invitee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS list_invitations;
+-- +goose StatementEnd
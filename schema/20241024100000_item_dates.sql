@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS start_at     TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS due_at       TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at   TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE todo_items SET completed_at = NOW() WHERE done AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS todo_items_due_at_idx ON todo_items (due_at) WHERE NOT done;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS todo_items_due_at_idx;
+
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS start_at,
+    DROP COLUMN IF EXISTS due_at,
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'none'
+        CHECK (priority IN ('none', 'low', 'medium', 'high', 'urgent'));
+
+CREATE TABLE IF NOT EXISTS labels
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    name       VARCHAR(255)                                NOT NULL,
+    color      VARCHAR(7)                                  NOT NULL,
+    created_at TIMESTAMP                                   NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS items_labels
+(
+    item_id  INT REFERENCES todo_items (id) ON DELETE CASCADE NOT NULL,
+    label_id INT REFERENCES labels (id) ON DELETE CASCADE     NOT NULL,
+    PRIMARY KEY (item_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS items_labels_label_id_idx ON items_labels (label_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS items_labels;
+DROP TABLE IF EXISTS labels;
+
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS subtasks
+(
+    id         SERIAL PRIMARY KEY,
+    item_id    INT REFERENCES todo_items (id) ON DELETE CASCADE NOT NULL,
+    title      VARCHAR(255)                                     NOT NULL,
+    done       BOOLEAN                                          NOT NULL DEFAULT FALSE,
+    position   INT                                              NOT NULL,
+    created_at TIMESTAMP                                        NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS subtasks_item_id_idx ON subtasks (item_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subtasks;
+-- +goose StatementEnd
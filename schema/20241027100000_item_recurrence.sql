@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS recurrence VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS occurrence INT     NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS recurred   BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS recurrence,
+    DROP COLUMN IF EXISTS occurrence,
+    DROP COLUMN IF EXISTS recurred;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE lists_items
+    ADD COLUMN IF NOT EXISTS position BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE users_lists
+    ADD COLUMN IF NOT EXISTS position BIGINT NOT NULL DEFAULT 0;
+
+UPDATE lists_items li SET position = o.position
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY list_id ORDER BY item_id) * 1024 AS position FROM lists_items) o
+WHERE li.id = o.id;
+
+UPDATE users_lists ul SET position = o.position
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY list_id) * 1024 AS position FROM users_lists) o
+WHERE ul.id = o.id;
+
+CREATE INDEX IF NOT EXISTS lists_items_position_idx ON lists_items (list_id, position);
+CREATE INDEX IF NOT EXISTS users_lists_position_idx ON users_lists (user_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS lists_items_position_idx;
+DROP INDEX IF EXISTS users_lists_position_idx;
+
+ALTER TABLE lists_items
+    DROP COLUMN IF EXISTS position;
+
+ALTER TABLE users_lists
+    DROP COLUMN IF EXISTS position;
+-- +goose StatementEnd
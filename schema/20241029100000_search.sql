@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todo_lists
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED;
+
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS todo_lists_search_idx ON todo_lists USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS todo_items_search_idx ON todo_items USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS todo_lists_search_idx;
+DROP INDEX IF EXISTS todo_items_search_idx;
+
+ALTER TABLE todo_lists
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
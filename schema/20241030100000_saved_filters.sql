@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS saved_filters
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    name       VARCHAR(255)                                NOT NULL,
+    expression JSONB                                       NOT NULL,
+    created_at TIMESTAMP                                   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP                                   NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS saved_filters;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE todo_lists
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- deleted_with_list marks the items that went to the trash with their list
+-- and come back when the list is restored
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS deleted_at        TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_with_list BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS todo_lists_deleted_at_idx ON todo_lists (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS todo_items_deleted_at_idx ON todo_items (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS todo_lists_deleted_at_idx;
+DROP INDEX IF EXISTS todo_items_deleted_at_idx;
+
+ALTER TABLE todo_lists
+    DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS deleted_with_list;
+-- +goose StatementEnd
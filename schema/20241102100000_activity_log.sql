@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS activity_log
+(
+    id          SERIAL PRIMARY KEY,
+    actor_id    INT REFERENCES users (id) ON DELETE SET NULL,
+    entity_type VARCHAR(16)                                      NOT NULL,
+    entity_id   INT                                              NOT NULL,
+    list_id     INT REFERENCES todo_lists (id) ON DELETE CASCADE NOT NULL,
+    action      VARCHAR(16)                                      NOT NULL,
+    changes     JSONB,
+    created_at  TIMESTAMP                                        NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS activity_log_list_idx ON activity_log (list_id, created_at, id);
+CREATE INDEX IF NOT EXISTS activity_log_entity_idx ON activity_log (entity_type, entity_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS activity_log;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comments
+(
+    id         SERIAL PRIMARY KEY,
+    item_id    INT REFERENCES todo_items (id) ON DELETE CASCADE NOT NULL,
+    author_id  INT REFERENCES users (id) ON DELETE SET NULL,
+    body       TEXT                                             NOT NULL,
+    mentions   TEXT[]                                           NOT NULL DEFAULT '{}',
+    edited     BOOLEAN                                          NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP                                        NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP                                        NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS comments_item_id_idx ON comments (item_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comments;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS attachments
+(
+    id           SERIAL PRIMARY KEY,
+    item_id      INT REFERENCES todo_items (id) ON DELETE CASCADE NOT NULL,
+    uploader_id  INT REFERENCES users (id) ON DELETE SET NULL,
+    filename     VARCHAR(255)                                     NOT NULL,
+    content_type VARCHAR(255)                                     NOT NULL,
+    size         BIGINT                                           NOT NULL,
+    blob_key     VARCHAR(128)                                     NOT NULL UNIQUE,
+    created_at   TIMESTAMP                                        NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS attachments_item_id_idx ON attachments (item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attachments;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- rotated_refresh_tokens keeps the refresh tokens a session had before, so a
+-- token presented again after rotation is recognized as reused
+CREATE TABLE IF NOT EXISTS rotated_refresh_tokens
+(
+    refresh_token_hash VARCHAR(255) PRIMARY KEY,
+    session_id         INT REFERENCES sessions (id) ON DELETE CASCADE NOT NULL,
+    rotated_at         TIMESTAMPTZ                                    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS rotated_refresh_tokens_session_id_idx ON rotated_refresh_tokens (session_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rotated_refresh_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- lists created before this migration get the time it ran
+ALTER TABLE todo_lists
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE todo_lists
+    DROP COLUMN IF EXISTS created_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE list_invitations
+    DROP CONSTRAINT IF EXISTS list_invitations_status_check,
+    ADD CONSTRAINT list_invitations_status_check CHECK (status IN ('pending', 'accepted', 'declined', 'expired'));
+
+-- invitations can only be declined before they expire, so the ones answered
+-- later were closed by a new invitation
+UPDATE list_invitations
+SET status = 'expired'
+WHERE status = 'declined'
+  AND responded_at >= expires_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE list_invitations
+SET status = 'declined'
+WHERE status = 'expired';
+
+ALTER TABLE list_invitations
+    DROP CONSTRAINT IF EXISTS list_invitations_status_check,
+    ADD CONSTRAINT list_invitations_status_check CHECK (status IN ('pending', 'accepted', 'declined'));
+-- +goose StatementEnd
@@ -0,0 +1,78 @@
+-- +goose Up
+-- +goose StatementBegin
+-- search_settings holds the text search configuration the search_vector
+-- columns are built with, the application sets it from its config on start
+CREATE TABLE IF NOT EXISTS search_settings
+(
+    id     BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    config regconfig NOT NULL
+);
+
+INSERT INTO search_settings (config)
+VALUES ('english')
+ON CONFLICT DO NOTHING;
+
+CREATE OR REPLACE FUNCTION update_search_vector() RETURNS TRIGGER AS
+$$
+DECLARE
+    search_config regconfig;
+BEGIN
+    SELECT config INTO search_config FROM search_settings;
+    NEW.search_vector :=
+            setweight(to_tsvector(search_config, coalesce(NEW.title, '')), 'A') ||
+            setweight(to_tsvector(search_config, coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- the vectors keep their values, from now on the triggers maintain them
+ALTER TABLE todo_lists
+    ALTER COLUMN search_vector DROP EXPRESSION;
+
+ALTER TABLE todo_items
+    ALTER COLUMN search_vector DROP EXPRESSION;
+
+CREATE TRIGGER todo_lists_search_vector
+    BEFORE INSERT OR UPDATE OF title, description
+    ON todo_lists
+    FOR EACH ROW
+EXECUTE FUNCTION update_search_vector();
+
+CREATE TRIGGER todo_items_search_vector
+    BEFORE INSERT OR UPDATE OF title, description
+    ON todo_items
+    FOR EACH ROW
+EXECUTE FUNCTION update_search_vector();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS todo_lists_search_vector ON todo_lists;
+DROP TRIGGER IF EXISTS todo_items_search_vector ON todo_items;
+DROP FUNCTION IF EXISTS update_search_vector();
+DROP TABLE IF EXISTS search_settings;
+
+DROP INDEX IF EXISTS todo_lists_search_idx;
+DROP INDEX IF EXISTS todo_items_search_idx;
+
+ALTER TABLE todo_lists
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE todo_items
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE todo_lists
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED;
+
+ALTER TABLE todo_items
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS todo_lists_search_idx ON todo_lists USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS todo_items_search_idx ON todo_items USING GIN (search_vector);
+-- +goose StatementEnd
@@ -0,0 +1,7 @@
+// Package schema embeds the goose migrations of the database.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var Migrations embed.FS
@@ -2,26 +2,37 @@ package TodoApp
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 )
 
 type Server struct {
 	server *http.Server
+	// cancel cancels the contexts of all requests, which aborts their queries.
+	cancel context.CancelFunc
 }
 
 func (s *Server) Run(port string, handler http.Handler) error {
+	baseCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
 	s.server = &http.Server{
 		Addr:           ":" + port,
 		Handler:        handler,
 		MaxHeaderBytes: 1 << 20, // 1 MB
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	return s.server.ListenAndServe()
 }
 
+// Shutdown waits for active requests until ctx is done and then cancels the
+// ones still running, so their queries do not outlive the server.
 func (s *Server) Shutdown(ctx context.Context) error {
+	defer s.cancel()
 	return s.server.Shutdown(ctx)
 }